@@ -18,6 +18,27 @@ const (
 	SignatureTypeV4        SignatureType = 4
 )
 
+// PayloadSigning selects how the Signature Version 4 covers request bodies.
+type PayloadSigning int
+
+const (
+	// PayloadSigningAuto signs the payload unless that would require
+	// buffering it. Seekable bodies are measured first; bodies that are
+	// larger than the unsigned threshold over TLS, or whose length remains
+	// unknown, are left unsigned. Other non-seekable bodies are streamed.
+	PayloadSigningAuto PayloadSigning = 0
+
+	// PayloadSigningHash always includes the payload hash in the signature.
+	// Seekable bodies are hashed in a separate pass, others are streamed.
+	PayloadSigningHash PayloadSigning = 1
+
+	// PayloadSigningUnsigned uses UNSIGNED-PAYLOAD as the payload hash.
+	PayloadSigningUnsigned PayloadSigning = 2
+
+	// PayloadSigningStreaming sends the body as signed aws-chunked chunks.
+	PayloadSigningStreaming PayloadSigning = 3
+)
+
 type Config struct {
 	HTTPClient HTTPClient
 
@@ -25,8 +46,9 @@ type Config struct {
 
 	Endpoint Endpoint
 
-	Credentials   Credentials
-	SignatureType SignatureType
+	Credentials    CredentialsProvider
+	SignatureType  SignatureType
+	PayloadSigning PayloadSigning
 }
 
 func (c Config) ToAWS() aws.Config {
@@ -44,20 +66,27 @@ func (c Config) ToAWS() aws.Config {
 			}, nil
 		}),
 		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			if c.Credentials == nil {
+				return aws.Credentials{}, nil
+			}
+
+			creds, err := c.Credentials.Retrieve(ctx)
+			if err != nil {
+				return aws.Credentials{}, err
+			}
+
 			return aws.Credentials{
-				AccessKeyID:     c.Credentials.AccessKey,
-				SecretAccessKey: c.Credentials.SecretKey,
+				AccessKeyID:     creds.AccessKey,
+				SecretAccessKey: creds.SecretKey,
+				SessionToken:    creds.SessionToken,
+				CanExpire:       !creds.Expires.IsZero(),
+				Expires:         creds.Expires,
 			}, nil
 		}),
 		HTTPClient: c.HTTPClient,
 	}
 }
 
-type Credentials struct {
-	AccessKey string
-	SecretKey string
-}
-
 type HTTPClient interface {
 	Do(*http.Request) (*http.Response, error)
 }
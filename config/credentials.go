@@ -0,0 +1,35 @@
+package config
+
+import (
+	"context"
+	"time"
+)
+
+// CredentialsProvider supplies the credentials used to sign each request.
+// Implementations must be safe for concurrent use.
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+type Credentials struct {
+	AccessKey string
+	SecretKey string
+
+	// SessionToken is set for temporary credentials, like the ones issued by
+	// AWS STS.
+	SessionToken string
+
+	// Expires is the time after which temporary credentials stop being valid.
+	// The zero value means that the credentials never expire.
+	Expires time.Time
+}
+
+// Expired reports whether the credentials are no longer valid at the given time.
+func (c Credentials) Expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !now.Before(c.Expires)
+}
+
+// Retrieve makes static Credentials usable as a CredentialsProvider.
+func (c Credentials) Retrieve(ctx context.Context) (Credentials, error) {
+	return c, nil
+}
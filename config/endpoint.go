@@ -6,6 +6,9 @@ import (
 	"strconv"
 )
 
+const defaultHTTPPort = 80
+const defaultHTTPSPort = 443
+
 type Endpoint struct {
 	Host string
 	Port int
@@ -24,19 +27,35 @@ func (e Endpoint) String() string {
 	return fmt.Sprintf("http%s://%s:%d", suffix, e.Host, e.Port)
 }
 
+// NewEndpointFromURL parses an http or https URL. When the URL has no port,
+// the default one of the scheme is used.
 func NewEndpointFromURL(url string) (e Endpoint, err error) {
 	u, err := neturl.Parse(url)
 	if err != nil {
-		err = fmt.Errorf("cannot parse testserver URL: %w", err)
+		err = fmt.Errorf("cannot parse endpoint URL: %w", err)
 		return
 	}
 
-	port, err := strconv.Atoi(u.Port())
-	if err != nil {
-		err = fmt.Errorf("cannot parse testserver URL port: %w", err)
+	var port int
+
+	switch u.Scheme {
+	case "http":
+		port = defaultHTTPPort
+	case "https":
+		port = defaultHTTPSPort
+	default:
+		err = fmt.Errorf("unsupported endpoint URL scheme: %q", u.Scheme)
 		return
 	}
 
+	if raw := u.Port(); raw != "" {
+		port, err = strconv.Atoi(raw)
+		if err != nil {
+			err = fmt.Errorf("cannot parse endpoint URL port: %w", err)
+			return
+		}
+	}
+
 	e = Endpoint{
 		Host:    u.Hostname(),
 		Port:    port,
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEndpointFromURL(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected Endpoint
+	}{
+		{url: "http://127.0.0.1:9000", expected: Endpoint{Host: "127.0.0.1", Port: 9000}},
+		{url: "https://s3.example.com", expected: Endpoint{Host: "s3.example.com", Port: 443, WithSSL: true}},
+		{url: "http://s3.example.com/", expected: Endpoint{Host: "s3.example.com", Port: 80}},
+		{url: "https://[::1]:8443", expected: Endpoint{Host: "::1", Port: 8443, WithSSL: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			e, err := NewEndpointFromURL(tc.url)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, e)
+		})
+	}
+
+	for _, url := range []string{"ftp://s3.example.com", "s3.example.com", "http://s3.example.com:port", "%zz"} {
+		_, err := NewEndpointFromURL(url)
+		require.Error(t, err, url)
+	}
+}
@@ -0,0 +1,88 @@
+package shared
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// sections maps the section names of an INI file to their properties.
+// Properties of a nested block, like "s3 =" followed by indented
+// "addressing_style = path", are flattened as "s3.addressing_style".
+type sections map[string]map[string]string
+
+// parseINI reads the INI dialect of the AWS shared config and credentials
+// files.
+func parseINI(r io.Reader) (sections, error) {
+	result := sections{}
+
+	var current map[string]string
+	var parent string
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			if line[len(line)-1] != ']' {
+				return nil, fmt.Errorf("line %d: unterminated section header", lineNumber)
+			}
+
+			name := strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+			if _, ok := result[name]; !ok {
+				result[name] = map[string]string{}
+			}
+			current = result[name]
+			parent = ""
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("line %d: property outside of a section", lineNumber)
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected 'key = value'", lineNumber)
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = trimInlineComment(strings.TrimSpace(value))
+
+		nested := raw[0] == ' ' || raw[0] == '\t'
+
+		switch {
+		case nested && parent != "":
+			current[parent+"."+key] = value
+		case value == "":
+			parent = key
+		default:
+			parent = ""
+			current[key] = value
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// trimInlineComment removes a comment which is separated from the value by
+// whitespace, so that values containing '#' are kept.
+func trimInlineComment(value string) string {
+	for _, marker := range []string{" #", "\t#", " ;", "\t;"} {
+		if i := strings.Index(value, marker); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+	}
+
+	return value
+}
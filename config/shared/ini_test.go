@@ -0,0 +1,50 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseINI(t *testing.T) {
+	const content = `
+# leading comment
+[default]
+region = eu-west-1
+output=json ; inline comment
+
+[profile   minio]
+endpoint_url = http://localhost:9000
+s3 =
+  addressing_style = path
+  signature_version = s3v4
+aws_secret_access_key = abc#def
+`
+
+	result, err := parseINI(strings.NewReader(content))
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		sections{
+			"default": {
+				"region": "eu-west-1",
+				"output": "json",
+			},
+			"profile minio": {
+				"endpoint_url":          "http://localhost:9000",
+				"s3.addressing_style":   "path",
+				"s3.signature_version":  "s3v4",
+				"aws_secret_access_key": "abc#def",
+			},
+		},
+		result,
+	)
+}
+
+func TestParseINIErrors(t *testing.T) {
+	for _, content := range []string{"key = value", "[default", "[default]\nno separator"} {
+		_, err := parseINI(strings.NewReader(content))
+		require.Error(t, err, content)
+	}
+}
@@ -0,0 +1,360 @@
+// Package shared loads a config.Config from the AWS shared config and
+// credentials files (~/.aws/config and ~/.aws/credentials).
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/lvjp/raw-s3-sdk-go/credentials"
+)
+
+const defaultProfile = "default"
+
+const (
+	AddressingStyleAuto    = "auto"
+	AddressingStylePath    = "path"
+	AddressingStyleVirtual = "virtual"
+)
+
+// Options selects the files and the profile to load. Empty fields fall back
+// to the AWS_PROFILE, AWS_CONFIG_FILE and AWS_SHARED_CREDENTIALS_FILE
+// environment variables, then to the defaults.
+type Options struct {
+	Profile         string
+	ConfigFile      string
+	CredentialsFile string
+}
+
+// Profile holds the settings of a profile, merged from both files.
+type Profile struct {
+	Name string
+
+	Region           string
+	EndpointURL      string
+	AddressingStyle  string
+	SignatureVersion string
+
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+
+	CredentialProcess string
+
+	RoleARN              string
+	SourceProfile        string
+	ExternalID           string
+	RoleSessionName      string
+	WebIdentityTokenFile string
+
+	// source is the resolved SourceProfile.
+	source *Profile
+}
+
+// Load builds a ready to use config.Config from the selected profile.
+func Load(opts Options) (config.Config, error) {
+	profile, err := LoadProfile(opts)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	return profile.Config()
+}
+
+// LoadProfile reads the selected profile. A missing file is not an error, but
+// a profile explicitly selected must exist in one of them.
+func LoadProfile(opts Options) (*Profile, error) {
+	opts, err := resolveOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	configSections, err := readINIFile(opts.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	credentialsSections, err := readINIFile(opts.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadProfile(opts, configSections, credentialsSections, map[string]bool{})
+}
+
+// loadProfile merges the named profile of both files, and resolves its source
+// profile. visited detects source profile cycles.
+func loadProfile(opts Options, configSections, credentialsSections sections, visited map[string]bool) (*Profile, error) {
+	configSection, inConfig := configSections["profile "+opts.Profile]
+	if !inConfig && opts.Profile == defaultProfile {
+		configSection, inConfig = configSections[defaultProfile]
+	}
+
+	credentialsSection, inCredentials := credentialsSections[opts.Profile]
+
+	if !inConfig && !inCredentials && opts.Profile != defaultProfile {
+		return nil, fmt.Errorf("profile %q not found in %s or %s", opts.Profile, opts.ConfigFile, opts.CredentialsFile)
+	}
+
+	// The credentials file takes precedence over the config file.
+	values := map[string]string{}
+	for key, value := range configSection {
+		values[key] = value
+	}
+	for key, value := range credentialsSection {
+		values[key] = value
+	}
+
+	p := newProfile(opts.Profile, values)
+	if p.RoleARN == "" || p.SourceProfile == "" {
+		return p, nil
+	}
+
+	// A profile may source itself to assume the role with its own keys.
+	if p.SourceProfile == p.Name {
+		source := *p
+		source.RoleARN = ""
+		p.source = &source
+		return p, nil
+	}
+
+	visited[p.Name] = true
+	if visited[p.SourceProfile] {
+		return nil, fmt.Errorf("profile %q: source profile cycle through %q", p.Name, p.SourceProfile)
+	}
+
+	opts.Profile = p.SourceProfile
+	source, err := loadProfile(opts, configSections, credentialsSections, visited)
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", p.Name, err)
+	}
+	p.source = source
+
+	return p, nil
+}
+
+func newProfile(name string, values map[string]string) *Profile {
+	p := &Profile{
+		Name: name,
+
+		Region:           values["region"],
+		EndpointURL:      values["endpoint_url"],
+		AddressingStyle:  values["s3.addressing_style"],
+		SignatureVersion: values["signature_version"],
+
+		AccessKey:    values["aws_access_key_id"],
+		SecretKey:    values["aws_secret_access_key"],
+		SessionToken: values["aws_session_token"],
+
+		CredentialProcess: values["credential_process"],
+
+		RoleARN:              values["role_arn"],
+		SourceProfile:        values["source_profile"],
+		ExternalID:           values["external_id"],
+		RoleSessionName:      values["role_session_name"],
+		WebIdentityTokenFile: values["web_identity_token_file"],
+	}
+
+	if endpointURL := values["s3.endpoint_url"]; endpointURL != "" {
+		p.EndpointURL = endpointURL
+	}
+
+	if signatureVersion := values["s3.signature_version"]; signatureVersion != "" {
+		p.SignatureVersion = signatureVersion
+	}
+
+	return p
+}
+
+// Config converts the profile. The AWS_REGION and AWS_DEFAULT_REGION
+// environment variables override the profile region.
+func (p *Profile) Config() (config.Config, error) {
+	region := firstNonEmpty(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), p.Region)
+
+	signatureType, err := parseSignatureVersion(p.SignatureVersion)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	endpoint, err := p.endpoint(region)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	return config.Config{
+		Region:        region,
+		Endpoint:      endpoint,
+		Credentials:   credentials.NewCache(p.credentialsProvider(region), credentials.DefaultExpiryWindow),
+		SignatureType: signatureType,
+	}, nil
+}
+
+// endpoint uses the profile endpoint URL, or the regional AWS endpoint. The
+// auto addressing style means virtual-hosted for AWS, and path style for
+// custom endpoints which often lack wildcard DNS records.
+func (p *Profile) endpoint(region string) (config.Endpoint, error) {
+	style := strings.ToLower(p.AddressingStyle)
+
+	switch style {
+	case "", AddressingStyleAuto, AddressingStylePath, AddressingStyleVirtual:
+	default:
+		return config.Endpoint{}, fmt.Errorf("profile %q: unsupported addressing style %q", p.Name, p.AddressingStyle)
+	}
+
+	if p.EndpointURL == "" {
+		if region == "" {
+			return config.Endpoint{}, fmt.Errorf("profile %q: a region or an endpoint URL is required", p.Name)
+		}
+
+		e, err := config.NewEndpointFromURL("https://s3." + region + ".amazonaws.com")
+		if err != nil {
+			return config.Endpoint{}, err
+		}
+		e.WithVirtualHost = style != AddressingStylePath
+
+		return e, nil
+	}
+
+	e, err := config.NewEndpointFromURL(p.EndpointURL)
+	if err != nil {
+		return config.Endpoint{}, fmt.Errorf("profile %q: %w", p.Name, err)
+	}
+	e.WithVirtualHost = style == AddressingStyleVirtual
+
+	return e, nil
+}
+
+// credentialsProvider returns the environment credentials first, then the web
+// identity ones set by the environment, then the ones configured by the
+// profile, and finally the container or instance role ones.
+func (p *Profile) credentialsProvider(region string) config.CredentialsProvider {
+	chain := credentials.NewChainProvider(credentials.EnvProvider{})
+
+	if tokenFile, roleARN := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"), os.Getenv("AWS_ROLE_ARN"); tokenFile != "" && roleARN != "" {
+		provider := credentials.NewWebIdentityProvider(roleARN, tokenFile)
+		provider.Endpoint, provider.Region = stsEndpoint(region)
+		provider.RoleSessionName = os.Getenv("AWS_ROLE_SESSION_NAME")
+		chain.Providers = append(chain.Providers, provider)
+	}
+
+	chain.Providers = append(chain.Providers, p.profileProviders(region)...)
+
+	if os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI") != "" || os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI") != "" {
+		chain.Providers = append(chain.Providers, credentials.NewECSProvider(nil))
+	} else {
+		chain.Providers = append(chain.Providers, credentials.NewIMDSProvider(nil))
+	}
+
+	return chain
+}
+
+// profileProviders returns the providers configured by the profile itself: an
+// assumed role, or its static keys and credential process.
+func (p *Profile) profileProviders(region string) []config.CredentialsProvider {
+	if p.RoleARN != "" {
+		endpoint, stsRegion := stsEndpoint(region)
+
+		if p.WebIdentityTokenFile != "" {
+			provider := credentials.NewWebIdentityProvider(p.RoleARN, p.WebIdentityTokenFile)
+			provider.Endpoint, provider.Region = endpoint, stsRegion
+			provider.RoleSessionName = p.RoleSessionName
+			return []config.CredentialsProvider{provider}
+		}
+
+		if p.source != nil {
+			source := credentials.NewChainProvider(p.source.profileProviders(region)...)
+
+			provider := credentials.NewAssumeRoleProvider(source, p.RoleARN)
+			provider.Endpoint, provider.Region = endpoint, stsRegion
+			provider.RoleSessionName = p.RoleSessionName
+			provider.ExternalID = p.ExternalID
+			return []config.CredentialsProvider{provider}
+		}
+	}
+
+	providers := []config.CredentialsProvider{
+		credentials.NewStaticProvider(p.AccessKey, p.SecretKey, p.SessionToken),
+	}
+
+	if p.CredentialProcess != "" {
+		providers = append(providers, credentials.NewProcessProvider(p.CredentialProcess))
+	}
+
+	return providers
+}
+
+// stsEndpoint returns the STS endpoint and its signing region. The
+// AWS_ENDPOINT_URL_STS environment variable overrides the endpoint.
+func stsEndpoint(region string) (string, string) {
+	stsRegion := firstNonEmpty(region, credentials.DefaultSTSRegion)
+
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL_STS"); endpoint != "" {
+		return endpoint, stsRegion
+	}
+
+	return credentials.STSEndpoint(region), stsRegion
+}
+
+func parseSignatureVersion(version string) (config.SignatureType, error) {
+	switch strings.ToLower(version) {
+	case "", "s3v4", "v4":
+		return config.SignatureTypeV4, nil
+	case "s3", "v2":
+		return config.SignatureTypeV2Header, nil
+	case "unsigned":
+		return config.SignatureTypeAnonymous, nil
+	default:
+		return 0, fmt.Errorf("unsupported signature version %q", version)
+	}
+}
+
+func resolveOptions(opts Options) (Options, error) {
+	opts.Profile = firstNonEmpty(opts.Profile, os.Getenv("AWS_PROFILE"), defaultProfile)
+	opts.ConfigFile = firstNonEmpty(opts.ConfigFile, os.Getenv("AWS_CONFIG_FILE"))
+	opts.CredentialsFile = firstNonEmpty(opts.CredentialsFile, os.Getenv("AWS_SHARED_CREDENTIALS_FILE"))
+
+	if opts.ConfigFile == "" || opts.CredentialsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return opts, fmt.Errorf("cannot locate the shared config files: %w", err)
+		}
+
+		opts.ConfigFile = firstNonEmpty(opts.ConfigFile, filepath.Join(home, ".aws", "config"))
+		opts.CredentialsFile = firstNonEmpty(opts.CredentialsFile, filepath.Join(home, ".aws", "credentials"))
+	}
+
+	return opts, nil
+}
+
+func readINIFile(path string) (sections, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return sections{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result, err := parseINI(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+
+	return result, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
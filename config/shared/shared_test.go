@@ -0,0 +1,280 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfigFile = `
+[default]
+region = us-west-2
+
+[profile minio]
+region = us-east-1
+endpoint_url = http://localhost:9000
+s3 =
+  addressing_style = path
+
+[profile legacy]
+endpoint_url = https://gateway.example.com
+signature_version = s3
+s3 =
+  addressing_style = virtual
+`
+
+const testCredentialsFile = `
+[default]
+aws_access_key_id = DEFAULTAKID
+aws_secret_access_key = DEFAULTSECRET
+
+[minio]
+aws_access_key_id = MINIOAKID
+aws_secret_access_key = MINIOSECRET
+aws_session_token = MINIOTOKEN
+`
+
+func setupFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	configFile := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(configFile, []byte(testConfigFile), 0o600))
+
+	credentialsFile := filepath.Join(dir, "credentials")
+	require.NoError(t, os.WriteFile(credentialsFile, []byte(testCredentialsFile), 0o600))
+
+	for _, name := range []string{"AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION", "AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY", "AWS_SECRET_KEY", "AWS_SESSION_TOKEN", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN", "AWS_ENDPOINT_URL_STS"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+}
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		profile       string
+		env           map[string]string
+		region        string
+		endpoint      config.Endpoint
+		signatureType config.SignatureType
+		credentials   config.Credentials
+	}{
+		{
+			profile:       "",
+			region:        "us-west-2",
+			endpoint:      config.Endpoint{Host: "s3.us-west-2.amazonaws.com", Port: 443, WithSSL: true, WithVirtualHost: true},
+			signatureType: config.SignatureTypeV4,
+			credentials:   config.Credentials{AccessKey: "DEFAULTAKID", SecretKey: "DEFAULTSECRET"},
+		},
+		{
+			profile:       "minio",
+			region:        "us-east-1",
+			endpoint:      config.Endpoint{Host: "localhost", Port: 9000},
+			signatureType: config.SignatureTypeV4,
+			credentials:   config.Credentials{AccessKey: "MINIOAKID", SecretKey: "MINIOSECRET", SessionToken: "MINIOTOKEN"},
+		},
+		{
+			profile:       "legacy",
+			env:           map[string]string{"AWS_REGION": "eu-central-1", "AWS_ACCESS_KEY_ID": "ENVAKID", "AWS_SECRET_ACCESS_KEY": "ENVSECRET"},
+			region:        "eu-central-1",
+			endpoint:      config.Endpoint{Host: "gateway.example.com", Port: 443, WithSSL: true, WithVirtualHost: true},
+			signatureType: config.SignatureTypeV2Header,
+			credentials:   config.Credentials{AccessKey: "ENVAKID", SecretKey: "ENVSECRET"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.profile, func(t *testing.T) {
+			setupFiles(t)
+			t.Setenv("AWS_PROFILE", tc.profile)
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := Load(Options{})
+			require.NoError(t, err)
+
+			require.Equal(t, tc.region, cfg.Region)
+			require.Equal(t, tc.endpoint, cfg.Endpoint)
+			require.Equal(t, tc.signatureType, cfg.SignatureType)
+
+			creds, err := cfg.Credentials.Retrieve(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, tc.credentials, creds)
+		})
+	}
+}
+
+func TestLoadOptions(t *testing.T) {
+	setupFiles(t)
+	t.Setenv("AWS_PROFILE", "legacy")
+
+	profile, err := LoadProfile(Options{Profile: "minio"})
+	require.NoError(t, err)
+	require.Equal(t, "minio", profile.Name)
+	require.Equal(t, "path", profile.AddressingStyle)
+
+	_, err = LoadProfile(Options{Profile: "missing"})
+	require.Error(t, err)
+
+	profile, err = LoadProfile(Options{ConfigFile: filepath.Join(t.TempDir(), "none"), CredentialsFile: filepath.Join(t.TempDir(), "none")})
+	require.Error(t, err, "AWS_PROFILE selects a profile which is not in the files")
+	require.Nil(t, profile)
+}
+
+func TestLoadErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"NoRegion":         "[default]\n",
+		"AddressingStyle":  "[default]\nregion = us-east-1\ns3 =\n  addressing_style = dns\n",
+		"SignatureVersion": "[default]\nregion = us-east-1\nsignature_version = s3v5\n",
+		"EndpointURL":      "[default]\nendpoint_url = ftp://example.com\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			setupFiles(t)
+
+			configFile := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(configFile, []byte(content), 0o600))
+
+			_, err := Load(Options{ConfigFile: configFile})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLoadCredentialProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test script needs a POSIX shell")
+	}
+
+	setupFiles(t)
+
+	configFile := filepath.Join(t.TempDir(), "config")
+	content := "[profile sso]\nregion = eu-west-1\ncredential_process = echo '{\"Version\": 1, \"AccessKeyId\": \"PROCESSAKID\", \"SecretAccessKey\": \"S\"}'\n"
+	require.NoError(t, os.WriteFile(configFile, []byte(content), 0o600))
+
+	cfg, err := Load(Options{Profile: "sso", ConfigFile: configFile})
+	require.NoError(t, err)
+
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "PROCESSAKID", creds.AccessKey)
+}
+
+// newSTSServer answers every action with credentials whose access key tells
+// the action and the key which signed the request.
+func newSTSServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+
+		signer := "anonymous"
+		if _, credential, ok := strings.Cut(r.Header.Get("Authorization"), "Credential="); ok {
+			signer, _, _ = strings.Cut(credential, "/")
+		}
+
+		action := r.PostForm.Get("Action")
+		fmt.Fprintf(
+			w,
+			"<%[1]sResponse><%[1]sResult><Credentials><AccessKeyId>%[1]s-%[2]s-%[3]s</AccessKeyId>"+
+				"<SecretAccessKey>S</SecretAccessKey><SessionToken>T</SessionToken>"+
+				"<Expiration>2099-01-01T00:00:00Z</Expiration></Credentials></%[1]sResult></%[1]sResponse>",
+			action,
+			signer,
+			r.PostForm.Get("RoleArn"),
+		)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("AWS_ENDPOINT_URL_STS", server.URL)
+}
+
+func TestLoadAssumeRole(t *testing.T) {
+	setupFiles(t)
+	newSTSServer(t)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token"), 0o600))
+
+	configFile := filepath.Join(t.TempDir(), "config")
+	content := `
+[profile base]
+region = eu-west-1
+aws_access_key_id = BASEAKID
+aws_secret_access_key = BASESECRET
+
+[profile admin]
+region = eu-west-1
+role_arn = admin
+source_profile = base
+
+[profile nested]
+region = eu-west-1
+role_arn = nested
+source_profile = admin
+
+[profile self]
+region = eu-west-1
+role_arn = self
+source_profile = self
+aws_access_key_id = SELFAKID
+aws_secret_access_key = SELFSECRET
+
+[profile web]
+region = eu-west-1
+role_arn = web
+web_identity_token_file = ` + tokenFile + `
+
+[profile loop]
+region = eu-west-1
+role_arn = loop
+source_profile = pool
+
+[profile pool]
+role_arn = pool
+source_profile = loop
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(content), 0o600))
+
+	testCases := map[string]string{
+		"admin":  "AssumeRole-BASEAKID-admin",
+		"nested": "AssumeRole-AssumeRole-BASEAKID-admin-nested",
+		"self":   "AssumeRole-SELFAKID-self",
+		"web":    "AssumeRoleWithWebIdentity-anonymous-web",
+	}
+
+	for profile, expected := range testCases {
+		t.Run(profile, func(t *testing.T) {
+			cfg, err := Load(Options{Profile: profile, ConfigFile: configFile})
+			require.NoError(t, err)
+
+			creds, err := cfg.Credentials.Retrieve(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, expected, creds.AccessKey)
+		})
+	}
+
+	t.Run("WebIdentityEnv", func(t *testing.T) {
+		t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", tokenFile)
+		t.Setenv("AWS_ROLE_ARN", "env")
+
+		cfg, err := Load(Options{Profile: "base", ConfigFile: configFile})
+		require.NoError(t, err)
+
+		creds, err := cfg.Credentials.Retrieve(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "AssumeRoleWithWebIdentity-anonymous-env", creds.AccessKey)
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		_, err := Load(Options{Profile: "loop", ConfigFile: configFile})
+		require.ErrorContains(t, err, "cycle")
+	})
+}
@@ -0,0 +1,145 @@
+package credentials
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+)
+
+// DefaultExpiryWindow is how long before their expiration cached credentials
+// are refreshed.
+const DefaultExpiryWindow = 5 * time.Minute
+
+// refreshTimeout bounds a refresh, which no longer depends on the context of
+// the caller that started it.
+const refreshTimeout = time.Minute
+
+var _ config.CredentialsProvider = (*Cache)(nil)
+
+// Cache keeps the credentials of a provider until they are about to expire.
+// Concurrent callers share a single refresh of the underlying provider.
+type Cache struct {
+	provider     config.CredentialsProvider
+	expiryWindow time.Duration
+
+	// now is the time source, replaced in tests.
+	now func() time.Time
+
+	mu      sync.Mutex
+	creds   *config.Credentials
+	window  time.Duration
+	refresh *refreshCall
+}
+
+type refreshCall struct {
+	done  chan struct{}
+	creds config.Credentials
+	err   error
+}
+
+// NewCache wraps the provider. Credentials are refreshed expiryWindow before
+// they expire, or halfway through their lifetime when it is shorter than
+// twice expiryWindow.
+func NewCache(provider config.CredentialsProvider, expiryWindow time.Duration) *Cache {
+	return &Cache{
+		provider:     provider,
+		expiryWindow: expiryWindow,
+		now:          time.Now,
+	}
+}
+
+func (c *Cache) Retrieve(ctx context.Context) (config.Credentials, error) {
+	c.mu.Lock()
+
+	if c.creds != nil && !c.creds.Expired(c.now().Add(c.window)) {
+		creds := *c.creds
+		c.mu.Unlock()
+		return creds, nil
+	}
+
+	call := c.refresh
+	leader := call == nil
+	if leader {
+		call = &refreshCall{done: make(chan struct{})}
+		c.refresh = call
+	}
+
+	c.mu.Unlock()
+
+	if leader {
+		// The refresh is shared: canceling the caller which started it must
+		// not fail the others, so it runs detached from its cancellation.
+		go c.doRefresh(detachedContext{ctx}, call)
+	}
+
+	select {
+	case <-call.done:
+		return call.creds, call.err
+	case <-ctx.Done():
+		return config.Credentials{}, ctx.Err()
+	}
+}
+
+// Invalidate forces the next Retrieve to refresh the credentials.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.creds = nil
+}
+
+func (c *Cache) doRefresh(ctx context.Context, call *refreshCall) {
+	ctx, cancel := context.WithTimeout(ctx, refreshTimeout)
+	defer cancel()
+
+	creds, err := c.provider.Retrieve(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case err == nil:
+		c.creds = &creds
+		c.window = c.refreshWindow(creds)
+		call.creds = creds
+	case c.creds != nil && !c.creds.Expired(c.now()):
+		// Keep serving the previous credentials while they are still valid.
+		call.creds = *c.creds
+	default:
+		call.err = err
+	}
+
+	c.refresh = nil
+	close(call.done)
+}
+
+// refreshWindow clamps the expiry window to half the remaining lifetime of
+// the credentials, so that short-lived ones are not already due for a
+// refresh when they are stored.
+func (c *Cache) refreshWindow(creds config.Credentials) time.Duration {
+	if creds.Expires.IsZero() {
+		return c.expiryWindow
+	}
+
+	if half := creds.Expires.Sub(c.now()) / 2; half < c.expiryWindow {
+		return half
+	}
+
+	return c.expiryWindow
+}
+
+// detachedContext keeps the values of its parent but not its deadline nor its
+// cancellation, as context.WithoutCancel does from Go 1.21.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (d detachedContext) Value(key any) any {
+	return d.parent.Value(key)
+}
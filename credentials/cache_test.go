@@ -0,0 +1,193 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+	var calls int32
+	var fail atomic.Bool
+
+	provider := providerFunc(func(ctx context.Context) (config.Credentials, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if fail.Load() {
+			return config.Credentials{}, errors.New("refresh failed")
+		}
+
+		return config.Credentials{
+			AccessKey: "AKID",
+			SecretKey: "SECRET",
+			Expires:   now.Add(time.Hour + time.Duration(n)*time.Second),
+		}, nil
+	})
+
+	cache := NewCache(provider, 10*time.Minute)
+	cache.now = func() time.Time { return now }
+
+	creds, err := cache.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, now.Add(time.Hour+time.Second), creds.Expires)
+
+	_, err = cache.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls), "cached")
+
+	// Within the expiry window, the credentials are refreshed.
+	now = now.Add(55 * time.Minute)
+	creds, err = cache.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	require.Equal(t, now.Add(time.Hour+2*time.Second), creds.Expires)
+
+	// A failed refresh keeps serving credentials which are not expired yet.
+	now = now.Add(58 * time.Minute)
+	fail.Store(true)
+	creds, err = cache.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "AKID", creds.AccessKey)
+
+	now = now.Add(time.Hour)
+	_, err = cache.Retrieve(context.Background())
+	require.Error(t, err)
+
+	fail.Store(false)
+	cache.Invalidate()
+	_, err = cache.Retrieve(context.Background())
+	require.NoError(t, err)
+}
+
+// TestCacheShortLived caches credentials living less than the expiry window:
+// they are refreshed halfway through their lifetime rather than on every
+// Retrieve.
+func TestCacheShortLived(t *testing.T) {
+	now := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+	var calls int32
+
+	provider := providerFunc(func(ctx context.Context) (config.Credentials, error) {
+		atomic.AddInt32(&calls, 1)
+		return config.Credentials{AccessKey: "AKID", Expires: now.Add(time.Minute)}, nil
+	})
+
+	cache := NewCache(provider, DefaultExpiryWindow)
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, err := cache.Retrieve(context.Background())
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	now = now.Add(29 * time.Second)
+	_, err := cache.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	now = now.Add(time.Second)
+	_, err = cache.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestCacheSingleFlight(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+
+	provider := providerFunc(func(ctx context.Context) (config.Credentials, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return config.Credentials{AccessKey: "AKID", SecretKey: "SECRET"}, nil
+	})
+
+	cache := NewCache(provider, DefaultExpiryWindow)
+
+	const callers = 32
+	var wg sync.WaitGroup
+	wg.Add(callers)
+
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer wg.Done()
+			creds, err := cache.Retrieve(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, "AKID", creds.AccessKey)
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestCacheContextCanceled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	provider := providerFunc(func(ctx context.Context) (config.Credentials, error) {
+		<-release
+		return config.Credentials{}, nil
+	})
+
+	cache := NewCache(provider, DefaultExpiryWindow)
+
+	go func() {
+		_, _ = cache.Retrieve(context.Background())
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := cache.Retrieve(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCacheLeaderCanceled(t *testing.T) {
+	type key struct{}
+	release := make(chan struct{})
+
+	provider := providerFunc(func(ctx context.Context) (config.Credentials, error) {
+		require.Equal(t, "value", ctx.Value(key{}))
+
+		select {
+		case <-release:
+			return config.Credentials{AccessKey: "AKID", SecretKey: "SECRET"}, nil
+		case <-ctx.Done():
+			return config.Credentials{}, ctx.Err()
+		}
+	})
+
+	cache := NewCache(provider, DefaultExpiryWindow)
+
+	leaderCtx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	leaderErr := make(chan error)
+	go func() {
+		_, err := cache.Retrieve(leaderCtx)
+		leaderErr <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	waiterCreds := make(chan config.Credentials)
+	go func() {
+		creds, err := cache.Retrieve(context.Background())
+		require.NoError(t, err)
+		waiterCreds <- creds
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	require.ErrorIs(t, <-leaderErr, context.Canceled)
+
+	close(release)
+	require.Equal(t, "AKID", (<-waiterCreds).AccessKey)
+}
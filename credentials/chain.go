@@ -0,0 +1,40 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+)
+
+var _ config.CredentialsProvider = (*ChainProvider)(nil)
+
+// ChainProvider tries its providers in order and returns the credentials of
+// the first one which succeeds.
+type ChainProvider struct {
+	Providers []config.CredentialsProvider
+}
+
+func NewChainProvider(providers ...config.CredentialsProvider) *ChainProvider {
+	return &ChainProvider{Providers: providers}
+}
+
+func (c *ChainProvider) Retrieve(ctx context.Context) (config.Credentials, error) {
+	errs := make([]error, 0, len(c.Providers))
+
+	for _, provider := range c.Providers {
+		creds, err := provider.Retrieve(ctx)
+		if err == nil {
+			return creds, nil
+		}
+
+		if ctx.Err() != nil {
+			return config.Credentials{}, ctx.Err()
+		}
+
+		errs = append(errs, err)
+	}
+
+	return config.Credentials{}, fmt.Errorf("%w: no provider in the chain succeeded: %w", ErrCredentialsNotFound, errors.Join(errs...))
+}
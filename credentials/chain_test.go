@@ -0,0 +1,37 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+type providerFunc func(ctx context.Context) (config.Credentials, error)
+
+func (f providerFunc) Retrieve(ctx context.Context) (config.Credentials, error) {
+	return f(ctx)
+}
+
+func TestChainProvider(t *testing.T) {
+	errBroken := errors.New("broken")
+	broken := providerFunc(func(ctx context.Context) (config.Credentials, error) {
+		return config.Credentials{}, errBroken
+	})
+	empty := NewStaticProvider("", "", "")
+	first := NewStaticProvider("FIRST", "SECRET", "")
+	second := NewStaticProvider("SECOND", "SECRET", "")
+
+	creds, err := NewChainProvider(empty, broken, first, second).Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "FIRST", creds.AccessKey)
+
+	_, err = NewChainProvider(empty, broken).Retrieve(context.Background())
+	require.ErrorIs(t, err, ErrCredentialsNotFound)
+	require.ErrorIs(t, err, errBroken)
+
+	_, err = NewChainProvider().Retrieve(context.Background())
+	require.ErrorIs(t, err, ErrCredentialsNotFound)
+}
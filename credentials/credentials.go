@@ -0,0 +1,9 @@
+// Package credentials provides the config.CredentialsProvider implementations
+// used to sign requests.
+package credentials
+
+import "errors"
+
+// ErrCredentialsNotFound is returned by providers which have nothing to offer,
+// so that a Chain can move on to the next one.
+var ErrCredentialsNotFound = errors.New("credentials not found")
@@ -0,0 +1,137 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+)
+
+// DefaultECSEndpoint is the base URL the ECS relative URI is resolved against.
+const DefaultECSEndpoint = "http://169.254.170.2"
+
+// DefaultECSTimeout bounds a whole retrieval, so that a chain does not hang
+// when the container endpoint is unreachable.
+const DefaultECSTimeout = 5 * time.Second
+
+// allowedContainerHosts are the link-local addresses ECS and EKS serve their
+// credentials on over plain HTTP.
+var allowedContainerHosts = map[string]struct{}{
+	"169.254.170.2":  {},
+	"169.254.170.23": {},
+	"fd00:ec2::23":   {},
+}
+
+var _ config.CredentialsProvider = (*ECSProvider)(nil)
+
+// ECSProvider retrieves the credentials of the task role from the container
+// credentials endpoint.
+type ECSProvider struct {
+	HTTPClient config.HTTPClient
+
+	// Endpoint is the base URL of RelativeURI.
+	Endpoint    string
+	RelativeURI string
+
+	// FullURI is used when RelativeURI is empty, as RelativeURI takes
+	// precedence.
+	FullURI string
+
+	// AuthorizationToken is sent as the Authorization header, when set.
+	AuthorizationToken string
+
+	// AuthorizationTokenFile is read on each retrieval, and takes precedence
+	// over AuthorizationToken, as the token may be rotated.
+	AuthorizationTokenFile string
+
+	Timeout time.Duration
+}
+
+// NewECSProvider reads the AWS_CONTAINER_CREDENTIALS_RELATIVE_URI,
+// AWS_CONTAINER_CREDENTIALS_FULL_URI, AWS_CONTAINER_AUTHORIZATION_TOKEN and
+// AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE environment variables.
+func NewECSProvider(client config.HTTPClient) *ECSProvider {
+	return &ECSProvider{
+		HTTPClient:             client,
+		Endpoint:               DefaultECSEndpoint,
+		RelativeURI:            os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"),
+		FullURI:                os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI"),
+		AuthorizationToken:     os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"),
+		AuthorizationTokenFile: os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"),
+		Timeout:                DefaultECSTimeout,
+	}
+}
+
+func (p *ECSProvider) Retrieve(ctx context.Context) (config.Credentials, error) {
+	url, err := p.url()
+	if err != nil {
+		return config.Credentials{}, err
+	}
+
+	token := p.AuthorizationToken
+	if p.AuthorizationTokenFile != "" {
+		content, err := os.ReadFile(p.AuthorizationTokenFile)
+		if err != nil {
+			return config.Credentials{}, fmt.Errorf("cannot read the container authorization token: %w", err)
+		}
+		token = strings.TrimSpace(string(content))
+	}
+
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", token)
+	}
+
+	body, err := doMetadataRequest(ctx, p.HTTPClient, http.MethodGet, url, header)
+	if err != nil {
+		return config.Credentials{}, fmt.Errorf("cannot get the container credentials: %w", err)
+	}
+
+	return decodeMetadataCredentials(body)
+}
+
+func (p *ECSProvider) url() (string, error) {
+	if p.RelativeURI != "" {
+		return strings.TrimSuffix(p.Endpoint, "/") + p.RelativeURI, nil
+	}
+
+	if p.FullURI == "" {
+		return "", fmt.Errorf("%w: no container credentials URI", ErrCredentialsNotFound)
+	}
+
+	u, err := neturl.Parse(p.FullURI)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse the container credentials URI: %w", err)
+	}
+
+	if u.Scheme != "https" && !isAllowedContainerHost(u.Hostname()) {
+		return "", fmt.Errorf("container credentials URI must use https or a loopback host: %s", p.FullURI)
+	}
+
+	return p.FullURI, nil
+}
+
+func isAllowedContainerHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	if _, ok := allowedContainerHosts[host]; ok {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,113 @@
+package credentials
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newECSServer(t *testing.T, path, token string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.Header.Get("Authorization") != token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(metadataCredentialsJSON))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestECSProvider(t *testing.T) {
+	t.Run("RelativeURI", func(t *testing.T) {
+		server := newECSServer(t, "/v2/credentials/task", "")
+
+		t.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "/v2/credentials/task")
+		t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN", "")
+		t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE", "")
+
+		provider := NewECSProvider(server.Client())
+		provider.Endpoint = server.URL
+
+		creds, err := provider.Retrieve(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, metadataCredentialsExpected, creds)
+	})
+
+	t.Run("FullURI", func(t *testing.T) {
+		server := newECSServer(t, "/credentials", "secret-token")
+
+		t.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "")
+		t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", server.URL+"/credentials")
+		t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN", "secret-token")
+		t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE", "")
+
+		creds, err := NewECSProvider(server.Client()).Retrieve(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, metadataCredentialsExpected, creds)
+	})
+
+	t.Run("TokenFile", func(t *testing.T) {
+		server := newECSServer(t, "/credentials", "file-token")
+
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(tokenFile, []byte("file-token\n"), 0o600))
+
+		provider := &ECSProvider{
+			HTTPClient:             server.Client(),
+			FullURI:                server.URL + "/credentials",
+			AuthorizationToken:     "ignored",
+			AuthorizationTokenFile: tokenFile,
+		}
+
+		creds, err := provider.Retrieve(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, metadataCredentialsExpected, creds)
+	})
+}
+
+func TestECSProviderErrors(t *testing.T) {
+	testCases := map[string]struct {
+		provider ECSProvider
+		expected string
+	}{
+		"RemoteHTTP":   {provider: ECSProvider{FullURI: "http://example.com/credentials"}, expected: "https or a loopback"},
+		"MissingToken": {provider: ECSProvider{FullURI: "http://127.0.0.1/", AuthorizationTokenFile: "/nonexistent"}, expected: "authorization token"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := tc.provider.Retrieve(context.Background())
+			require.ErrorContains(t, err, tc.expected)
+		})
+	}
+
+	t.Run("NoURI", func(t *testing.T) {
+		_, err := (&ECSProvider{}).Retrieve(context.Background())
+		require.ErrorIs(t, err, ErrCredentialsNotFound)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		t.Cleanup(server.Close)
+
+		provider := &ECSProvider{HTTPClient: server.Client(), FullURI: server.URL, Timeout: 50 * time.Millisecond}
+		_, err := provider.Retrieve(context.Background())
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,44 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+)
+
+var _ config.CredentialsProvider = EnvProvider{}
+
+// EnvProvider reads the credentials from the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables. The
+// legacy AWS_ACCESS_KEY and AWS_SECRET_KEY names are also accepted.
+type EnvProvider struct{}
+
+func (EnvProvider) Retrieve(ctx context.Context) (config.Credentials, error) {
+	creds := config.Credentials{
+		AccessKey:    lookupEnv("AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY"),
+		SecretKey:    lookupEnv("AWS_SECRET_ACCESS_KEY", "AWS_SECRET_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+
+	if creds.AccessKey == "" {
+		return config.Credentials{}, fmt.Errorf("%w: AWS_ACCESS_KEY_ID is not set", ErrCredentialsNotFound)
+	}
+
+	if creds.SecretKey == "" {
+		return config.Credentials{}, fmt.Errorf("%w: AWS_SECRET_ACCESS_KEY is not set", ErrCredentialsNotFound)
+	}
+
+	return creds, nil
+}
+
+func lookupEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,34 @@
+package credentials
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvProvider(t *testing.T) {
+	for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY", "AWS_SECRET_KEY", "AWS_SESSION_TOKEN"} {
+		t.Setenv(name, "")
+	}
+
+	_, err := EnvProvider{}.Retrieve(context.Background())
+	require.ErrorIs(t, err, ErrCredentialsNotFound)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	_, err = EnvProvider{}.Retrieve(context.Background())
+	require.ErrorIs(t, err, ErrCredentialsNotFound)
+
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "SECRET")
+	t.Setenv("AWS_SESSION_TOKEN", "TOKEN")
+	creds, err := EnvProvider{}.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, config.Credentials{AccessKey: "AKID", SecretKey: "SECRET", SessionToken: "TOKEN"}, creds)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_ACCESS_KEY", "LEGACY")
+	creds, err = EnvProvider{}.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "LEGACY", creds.AccessKey)
+}
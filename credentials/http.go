@@ -0,0 +1,95 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+)
+
+// maxMetadataResponse bounds the size of metadata endpoint responses.
+const maxMetadataResponse = 64 * 1024
+
+// metadataCredentials is the JSON document returned by both the instance and
+// the container metadata endpoints.
+type metadataCredentials struct {
+	Code            string
+	Message         string
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string
+	Token           string
+	Expiration      *time.Time
+}
+
+func (m *metadataCredentials) toCredentials() (config.Credentials, error) {
+	if m.Code != "" && m.Code != "Success" {
+		return config.Credentials{}, fmt.Errorf("metadata endpoint error %s: %s", m.Code, m.Message)
+	}
+
+	if m.AccessKeyID == "" || m.SecretAccessKey == "" {
+		return config.Credentials{}, fmt.Errorf("metadata endpoint response misses AccessKeyId or SecretAccessKey")
+	}
+
+	creds := config.Credentials{
+		AccessKey:    m.AccessKeyID,
+		SecretKey:    m.SecretAccessKey,
+		SessionToken: m.Token,
+	}
+
+	if m.Expiration != nil {
+		creds.Expires = *m.Expiration
+	}
+
+	return creds, nil
+}
+
+func httpClientOrDefault(client config.HTTPClient) config.HTTPClient {
+	if client == nil {
+		return http.DefaultClient
+	}
+
+	return client
+}
+
+// doMetadataRequest sends the request and returns the body of a successful
+// response.
+func doMetadataRequest(ctx context.Context, client config.HTTPClient, method, url string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	res, err := httpClientOrDefault(client).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxMetadataResponse))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s: unexpected status %s", method, url, res.Status)
+	}
+
+	return body, nil
+}
+
+func decodeMetadataCredentials(body []byte) (config.Credentials, error) {
+	var m metadataCredentials
+	if err := json.Unmarshal(body, &m); err != nil {
+		return config.Credentials{}, fmt.Errorf("cannot parse the metadata endpoint credentials: %w", err)
+	}
+
+	return m.toCredentials()
+}
@@ -0,0 +1,96 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+)
+
+// DefaultIMDSEndpoint is the address of the EC2 instance metadata service.
+const DefaultIMDSEndpoint = "http://169.254.169.254"
+
+// DefaultIMDSTimeout bounds a whole retrieval, so that a chain does not hang
+// when it does not run on an instance.
+const DefaultIMDSTimeout = 5 * time.Second
+
+const imdsTokenTTL = 6 * time.Hour
+
+const imdsCredentialsPath = "/latest/meta-data/iam/security-credentials/"
+
+var _ config.CredentialsProvider = (*IMDSProvider)(nil)
+
+// IMDSProvider retrieves the credentials of the instance role from the EC2
+// instance metadata service, using the IMDSv2 session token flow.
+type IMDSProvider struct {
+	HTTPClient config.HTTPClient
+
+	// Endpoint is the base URL of the metadata service.
+	Endpoint string
+
+	Timeout time.Duration
+}
+
+// NewIMDSProvider honours the AWS_EC2_METADATA_SERVICE_ENDPOINT environment
+// variable.
+func NewIMDSProvider(client config.HTTPClient) *IMDSProvider {
+	endpoint := os.Getenv("AWS_EC2_METADATA_SERVICE_ENDPOINT")
+	if endpoint == "" {
+		endpoint = DefaultIMDSEndpoint
+	}
+
+	return &IMDSProvider{
+		HTTPClient: client,
+		Endpoint:   endpoint,
+		Timeout:    DefaultIMDSTimeout,
+	}
+}
+
+func (p *IMDSProvider) Retrieve(ctx context.Context) (config.Credentials, error) {
+	if disabled, _ := strconv.ParseBool(os.Getenv("AWS_EC2_METADATA_DISABLED")); disabled {
+		return config.Credentials{}, fmt.Errorf("%w: instance metadata service is disabled", ErrCredentialsNotFound)
+	}
+
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	endpoint := strings.TrimSuffix(p.Endpoint, "/")
+
+	token, err := doMetadataRequest(
+		ctx,
+		p.HTTPClient,
+		http.MethodPut,
+		endpoint+"/latest/api/token",
+		http.Header{"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": {strconv.Itoa(int(imdsTokenTTL / time.Second))}},
+	)
+	if err != nil {
+		return config.Credentials{}, fmt.Errorf("cannot get the instance metadata token: %w", err)
+	}
+
+	header := http.Header{"X-Aws-Ec2-Metadata-Token": {strings.TrimSpace(string(token))}}
+
+	roles, err := doMetadataRequest(ctx, p.HTTPClient, http.MethodGet, endpoint+imdsCredentialsPath, header)
+	if err != nil {
+		return config.Credentials{}, fmt.Errorf("cannot get the instance role: %w", err)
+	}
+
+	role, _, _ := strings.Cut(strings.TrimSpace(string(roles)), "\n")
+	if role == "" {
+		return config.Credentials{}, fmt.Errorf("%w: no role attached to the instance", ErrCredentialsNotFound)
+	}
+
+	body, err := doMetadataRequest(ctx, p.HTTPClient, http.MethodGet, endpoint+imdsCredentialsPath+role, header)
+	if err != nil {
+		return config.Credentials{}, fmt.Errorf("cannot get the instance role credentials: %w", err)
+	}
+
+	return decodeMetadataCredentials(body)
+}
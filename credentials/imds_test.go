@@ -0,0 +1,100 @@
+package credentials
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+const metadataCredentialsJSON = `{
+  "Code": "Success",
+  "LastUpdated": "2023-03-01T06:00:00Z",
+  "Type": "AWS-HMAC",
+  "AccessKeyId": "ASIAMETADATA",
+  "SecretAccessKey": "SECRET",
+  "Token": "TOKEN",
+  "Expiration": "2023-03-01T12:00:00Z"
+}`
+
+var metadataCredentialsExpected = config.Credentials{
+	AccessKey:    "ASIAMETADATA",
+	SecretKey:    "SECRET",
+	SessionToken: "TOKEN",
+	Expires:      time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC),
+}
+
+func newIMDSServer(t *testing.T, credentials string) *httptest.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/latest/api/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("X-Aws-Ec2-Metadata-Token-Ttl-Seconds") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte("session-token"))
+	})
+
+	mux.HandleFunc(imdsCredentialsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Aws-Ec2-Metadata-Token") != "session-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case imdsCredentialsPath:
+			_, _ = w.Write([]byte("my-role\n"))
+		case imdsCredentialsPath + "my-role":
+			_, _ = w.Write([]byte(credentials))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestIMDSProvider(t *testing.T) {
+	server := newIMDSServer(t, metadataCredentialsJSON)
+
+	t.Setenv("AWS_EC2_METADATA_SERVICE_ENDPOINT", server.URL)
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "")
+
+	creds, err := NewIMDSProvider(server.Client()).Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, metadataCredentialsExpected, creds)
+}
+
+func TestIMDSProviderErrors(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+		_, err := NewIMDSProvider(nil).Retrieve(context.Background())
+		require.ErrorIs(t, err, ErrCredentialsNotFound)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		server := newIMDSServer(t, `{"Code": "AssumeRoleUnauthorizedAccess", "Message": "denied"}`)
+
+		_, err := (&IMDSProvider{HTTPClient: server.Client(), Endpoint: server.URL}).Retrieve(context.Background())
+		require.ErrorContains(t, err, "AssumeRoleUnauthorizedAccess")
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		t.Cleanup(server.Close)
+
+		provider := &IMDSProvider{HTTPClient: server.Client(), Endpoint: server.URL, Timeout: 50 * time.Millisecond}
+		_, err := provider.Retrieve(context.Background())
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,148 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+)
+
+// DefaultProcessTimeout bounds the run time of a credential_process command.
+const DefaultProcessTimeout = time.Minute
+
+// processWaitDelay bounds the wait for the output pipes once the command has
+// been killed.
+const processWaitDelay = time.Second
+
+// maxProcessOutput bounds the size of the command output kept in memory.
+const maxProcessOutput = 64 * 1024
+
+const processOutputVersion = 1
+
+var _ config.CredentialsProvider = (*ProcessProvider)(nil)
+
+// ProcessProvider runs an external command, configured as credential_process
+// in the shared config file, and parses the credentials it prints as JSON.
+// Wrap it in a Cache to avoid running the command for each request.
+type ProcessProvider struct {
+	// Command is run through the system shell.
+	Command string
+	Timeout time.Duration
+}
+
+func NewProcessProvider(command string) *ProcessProvider {
+	return &ProcessProvider{
+		Command: command,
+		Timeout: DefaultProcessTimeout,
+	}
+}
+
+// processOutput is the documented format of the command output.
+type processOutput struct {
+	Version         int
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      *time.Time
+}
+
+func (p *ProcessProvider) Retrieve(ctx context.Context) (config.Credentials, error) {
+	if p.Command == "" {
+		return config.Credentials{}, fmt.Errorf("%w: no credential process command", ErrCredentialsNotFound)
+	}
+
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr limitedBuffer
+	stdout.limit = maxProcessOutput
+	stderr.limit = maxProcessOutput
+
+	cmd := shellCommand(ctx, p.Command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Children of the shell may outlive it and keep the output pipes open.
+	cmd.WaitDelay = processWaitDelay
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return config.Credentials{}, fmt.Errorf("credential process failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	if stdout.truncated {
+		return config.Credentials{}, fmt.Errorf("credential process output exceeds %d bytes", maxProcessOutput)
+	}
+
+	var output processOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return config.Credentials{}, fmt.Errorf("cannot parse the credential process output: %w", err)
+	}
+
+	if output.Version != processOutputVersion {
+		return config.Credentials{}, fmt.Errorf("unsupported credential process output version: %d", output.Version)
+	}
+
+	if output.AccessKeyID == "" || output.SecretAccessKey == "" {
+		return config.Credentials{}, errors.New("credential process output misses AccessKeyId or SecretAccessKey")
+	}
+
+	creds := config.Credentials{
+		AccessKey:    output.AccessKeyID,
+		SecretKey:    output.SecretAccessKey,
+		SessionToken: output.SessionToken,
+	}
+
+	if output.Expiration != nil {
+		creds.Expires = *output.Expiration
+	}
+
+	return creds, nil
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd.exe", "/C", command)
+	}
+
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// limitedBuffer keeps at most limit bytes and silently drops the rest, so
+// that a misbehaving command cannot exhaust the memory.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); len(p) > room {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
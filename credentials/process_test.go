@@ -0,0 +1,103 @@
+package credentials
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+func writeScript(t *testing.T, content string) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test scripts need a POSIX shell")
+	}
+
+	path := filepath.Join(t.TempDir(), "credential-process.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+content), 0o700))
+
+	return path
+}
+
+func TestProcessProvider(t *testing.T) {
+	script := writeScript(t, `cat <<JSON
+{
+  "Version": 1,
+  "AccessKeyId": "ASIAPROCESS",
+  "SecretAccessKey": "SECRET",
+  "SessionToken": "TOKEN",
+  "Expiration": "2023-03-01T12:00:00Z"
+}
+JSON
+`)
+
+	creds, err := NewProcessProvider(script + " --profile dev").Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		config.Credentials{
+			AccessKey:    "ASIAPROCESS",
+			SecretKey:    "SECRET",
+			SessionToken: "TOKEN",
+			Expires:      time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC),
+		},
+		creds,
+	)
+}
+
+func TestProcessProviderErrors(t *testing.T) {
+	testCases := map[string]struct {
+		script   string
+		expected string
+	}{
+		"ExitCode":   {script: "echo 'token expired' >&2\nexit 3", expected: "token expired"},
+		"NotJSON":    {script: "echo hello", expected: "cannot parse"},
+		"Version":    {script: `echo '{"Version": 2, "AccessKeyId": "A", "SecretAccessKey": "S"}'`, expected: "version: 2"},
+		"MissingKey": {script: `echo '{"Version": 1, "AccessKeyId": "A"}'`, expected: "misses"},
+		"TooLarge":   {script: `head -c 100000 /dev/zero`, expected: "exceeds"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewProcessProvider(writeScript(t, tc.script)).Retrieve(context.Background())
+			require.ErrorContains(t, err, tc.expected)
+		})
+	}
+
+	t.Run("NoCommand", func(t *testing.T) {
+		_, err := NewProcessProvider("").Retrieve(context.Background())
+		require.ErrorIs(t, err, ErrCredentialsNotFound)
+	})
+}
+
+func TestProcessProviderTimeout(t *testing.T) {
+	provider := NewProcessProvider(writeScript(t, "exec sleep 10"))
+	provider.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := provider.Retrieve(context.Background())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestProcessProviderCached(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "counter")
+	script := writeScript(t, `echo run >> "`+counter+`"
+echo '{"Version": 1, "AccessKeyId": "A", "SecretAccessKey": "S"}'
+`)
+
+	cache := NewCache(NewProcessProvider(script), DefaultExpiryWindow)
+	for i := 0; i < 3; i++ {
+		_, err := cache.Retrieve(context.Background())
+		require.NoError(t, err)
+	}
+
+	runs, err := os.ReadFile(counter)
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(string(runs), "run"))
+}
@@ -0,0 +1,33 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+)
+
+var _ config.CredentialsProvider = StaticProvider{}
+
+// StaticProvider returns the same credentials forever.
+type StaticProvider struct {
+	Value config.Credentials
+}
+
+func NewStaticProvider(accessKey, secretKey, sessionToken string) StaticProvider {
+	return StaticProvider{
+		Value: config.Credentials{
+			AccessKey:    accessKey,
+			SecretKey:    secretKey,
+			SessionToken: sessionToken,
+		},
+	}
+}
+
+func (p StaticProvider) Retrieve(ctx context.Context) (config.Credentials, error) {
+	if p.Value.AccessKey == "" || p.Value.SecretKey == "" {
+		return config.Credentials{}, fmt.Errorf("%w: static credentials are empty", ErrCredentialsNotFound)
+	}
+
+	return p.Value, nil
+}
@@ -0,0 +1,18 @@
+package credentials
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProvider(t *testing.T) {
+	creds, err := NewStaticProvider("AKID", "SECRET", "TOKEN").Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, config.Credentials{AccessKey: "AKID", SecretKey: "SECRET", SessionToken: "TOKEN"}, creds)
+
+	_, err = NewStaticProvider("", "SECRET", "").Retrieve(context.Background())
+	require.ErrorIs(t, err, ErrCredentialsNotFound)
+}
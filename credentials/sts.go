@@ -0,0 +1,258 @@
+package credentials
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	signv4 "github.com/lvjp/raw-s3-sdk-go/signing/v4"
+)
+
+// DefaultSTSEndpoint is the global STS endpoint, whose signing region is
+// DefaultSTSRegion.
+const DefaultSTSEndpoint = "https://sts.amazonaws.com"
+
+const DefaultSTSRegion = "us-east-1"
+
+const (
+	stsService = "sts"
+	stsVersion = "2011-06-15"
+)
+
+// STSEndpoint returns the regional STS endpoint, or the global one when the
+// region is empty.
+func STSEndpoint(region string) string {
+	if region == "" {
+		return DefaultSTSEndpoint
+	}
+
+	return "https://sts." + region + ".amazonaws.com"
+}
+
+// STSError is returned when STS answers with an error document.
+type STSError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+}
+
+func (e *STSError) Error() string {
+	return fmt.Sprintf("sts: %s (%d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+var stsSigner = signv4.NewSigner(stsService)
+
+var (
+	_ config.CredentialsProvider = (*AssumeRoleProvider)(nil)
+	_ config.CredentialsProvider = (*WebIdentityProvider)(nil)
+)
+
+// AssumeRoleProvider retrieves the temporary credentials of a role with the
+// STS AssumeRole action, signed with the Source credentials. Wrap it with
+// NewCache to refresh them before they expire.
+type AssumeRoleProvider struct {
+	HTTPClient config.HTTPClient
+	Endpoint   string
+	Region     string
+
+	Source config.CredentialsProvider
+
+	RoleARN         string
+	RoleSessionName string
+	ExternalID      string
+
+	// Duration is left to the STS default when zero.
+	Duration time.Duration
+}
+
+func NewAssumeRoleProvider(source config.CredentialsProvider, roleARN string) *AssumeRoleProvider {
+	return &AssumeRoleProvider{
+		Endpoint: DefaultSTSEndpoint,
+		Region:   DefaultSTSRegion,
+		Source:   source,
+		RoleARN:  roleARN,
+	}
+}
+
+func (p *AssumeRoleProvider) Retrieve(ctx context.Context) (config.Credentials, error) {
+	source, err := p.Source.Retrieve(ctx)
+	if err != nil {
+		return config.Credentials{}, fmt.Errorf("cannot retrieve the source credentials of %s: %w", p.RoleARN, err)
+	}
+
+	params := url.Values{
+		"Action":          {"AssumeRole"},
+		"RoleArn":         {p.RoleARN},
+		"RoleSessionName": {sessionNameOrDefault(p.RoleSessionName)},
+	}
+
+	if p.ExternalID != "" {
+		params.Set("ExternalId", p.ExternalID)
+	}
+
+	setDuration(params, p.Duration)
+
+	return callSTS(ctx, p.HTTPClient, p.Endpoint, p.Region, params, &source)
+}
+
+// WebIdentityProvider retrieves the temporary credentials of a role with the
+// STS AssumeRoleWithWebIdentity action. The token file is read on each
+// retrieval, as it is rotated by the platform. Wrap it with NewCache to
+// refresh the credentials before they expire.
+type WebIdentityProvider struct {
+	HTTPClient config.HTTPClient
+	Endpoint   string
+	Region     string
+
+	RoleARN         string
+	RoleSessionName string
+	TokenFile       string
+
+	// Duration is left to the STS default when zero.
+	Duration time.Duration
+}
+
+func NewWebIdentityProvider(roleARN, tokenFile string) *WebIdentityProvider {
+	return &WebIdentityProvider{
+		Endpoint:  DefaultSTSEndpoint,
+		Region:    DefaultSTSRegion,
+		RoleARN:   roleARN,
+		TokenFile: tokenFile,
+	}
+}
+
+func (p *WebIdentityProvider) Retrieve(ctx context.Context) (config.Credentials, error) {
+	token, err := os.ReadFile(p.TokenFile)
+	if err != nil {
+		return config.Credentials{}, fmt.Errorf("cannot read the web identity token: %w", err)
+	}
+
+	params := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"RoleArn":          {p.RoleARN},
+		"RoleSessionName":  {sessionNameOrDefault(p.RoleSessionName)},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+
+	setDuration(params, p.Duration)
+
+	// The token authenticates the call, which is not signed.
+	return callSTS(ctx, p.HTTPClient, p.Endpoint, p.Region, params, nil)
+}
+
+func sessionNameOrDefault(name string) string {
+	if name != "" {
+		return name
+	}
+
+	return "raw-s3-sdk-go-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+func setDuration(params url.Values, duration time.Duration) {
+	if duration > 0 {
+		params.Set("DurationSeconds", strconv.FormatInt(int64(duration/time.Second), 10))
+	}
+}
+
+type stsResponse struct {
+	// The result element is named after the action.
+	Result struct {
+		Credentials struct {
+			AccessKeyID     string `xml:"AccessKeyId"`
+			SecretAccessKey string
+			SessionToken    string
+			Expiration      time.Time
+		}
+	} `xml:",any"`
+}
+
+type stsErrorResponse struct {
+	Error struct {
+		Code    string
+		Message string
+	}
+	RequestID string `xml:"RequestId"`
+}
+
+// callSTS sends the query API action, signed with the credentials when they
+// are not nil.
+func callSTS(
+	ctx context.Context,
+	client config.HTTPClient,
+	endpoint, region string,
+	params url.Values,
+	credentials *config.Credentials,
+) (config.Credentials, error) {
+	params.Set("Version", stsVersion)
+	action := params.Get("Action")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return config.Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	if credentials != nil {
+		if err := stsSigner.Sign(req, *credentials, region); err != nil {
+			return config.Credentials{}, fmt.Errorf("cannot sign the %s request: %w", action, err)
+		}
+	}
+
+	res, err := httpClientOrDefault(client).Do(req)
+	if err != nil {
+		return config.Credentials{}, fmt.Errorf("cannot call %s: %w", action, err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxMetadataResponse))
+	if err != nil {
+		return config.Credentials{}, fmt.Errorf("cannot read the %s response: %w", action, err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return config.Credentials{}, decodeSTSError(res.StatusCode, body)
+	}
+
+	var payload stsResponse
+	if err := xml.Unmarshal(body, &payload); err != nil {
+		return config.Credentials{}, fmt.Errorf("cannot parse the %s response: %w", action, err)
+	}
+
+	result := payload.Result.Credentials
+	if result.AccessKeyID == "" || result.SecretAccessKey == "" {
+		return config.Credentials{}, fmt.Errorf("%s response misses AccessKeyId or SecretAccessKey", action)
+	}
+
+	return config.Credentials{
+		AccessKey:    result.AccessKeyID,
+		SecretKey:    result.SecretAccessKey,
+		SessionToken: result.SessionToken,
+		Expires:      result.Expiration,
+	}, nil
+}
+
+func decodeSTSError(statusCode int, body []byte) error {
+	stsErr := &STSError{StatusCode: statusCode}
+
+	var payload stsErrorResponse
+	if err := xml.Unmarshal(body, &payload); err == nil {
+		stsErr.Code = payload.Error.Code
+		stsErr.Message = payload.Error.Message
+		stsErr.RequestID = payload.RequestID
+	}
+
+	if stsErr.Code == "" {
+		stsErr.Code = strings.ReplaceAll(http.StatusText(statusCode), " ", "")
+	}
+
+	return stsErr
+}
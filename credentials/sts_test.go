@@ -0,0 +1,183 @@
+package credentials
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+const stsResponseTemplate = `<{action}Response xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <{action}Result>
+    <Credentials>
+      <AccessKeyId>ASIASTS</AccessKeyId>
+      <SecretAccessKey>STSSECRET</SecretAccessKey>
+      <SessionToken>STSTOKEN</SessionToken>
+      <Expiration>2023-03-01T12:00:00Z</Expiration>
+    </Credentials>
+    <AssumedRoleUser>
+      <Arn>arn:aws:sts::123456789012:assumed-role/demo/session</Arn>
+      <AssumedRoleId>AROA3XFRBF535PLBIFPI4:session</AssumedRoleId>
+    </AssumedRoleUser>
+  </{action}Result>
+  <ResponseMetadata>
+    <RequestId>c6104cbe-af31-11e0-8154-cbc7ccf896c7</RequestId>
+  </ResponseMetadata>
+</{action}Response>`
+
+const stsErrorTemplate = `<ErrorResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <Error>
+    <Type>Sender</Type>
+    <Code>AccessDenied</Code>
+    <Message>Not authorized to perform sts:AssumeRole</Message>
+  </Error>
+  <RequestId>d2b5e3b1-af31-11e0-8154-cbc7ccf896c7</RequestId>
+</ErrorResponse>`
+
+var stsExpected = config.Credentials{
+	AccessKey:    "ASIASTS",
+	SecretKey:    "STSSECRET",
+	SessionToken: "STSTOKEN",
+	Expires:      time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC),
+}
+
+// newSTSServer emulates the STS query API, checking that the request is
+// signed for STS when required.
+func newSTSServer(t *testing.T, check func(t *testing.T, r *http.Request)) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, stsVersion, r.PostForm.Get("Version"))
+
+		if r.PostForm.Get("RoleArn") == "arn:aws:iam::123456789012:role/denied" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(stsErrorTemplate))
+			return
+		}
+
+		check(t, r)
+
+		_, _ = w.Write([]byte(strings.ReplaceAll(stsResponseTemplate, "{action}", r.PostForm.Get("Action"))))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestAssumeRoleProvider(t *testing.T) {
+	server := newSTSServer(t, func(t *testing.T, r *http.Request) {
+		require.Equal(t, "AssumeRole", r.PostForm.Get("Action"))
+		require.Equal(t, "arn:aws:iam::123456789012:role/demo", r.PostForm.Get("RoleArn"))
+		require.Equal(t, "session", r.PostForm.Get("RoleSessionName"))
+		require.Equal(t, "external", r.PostForm.Get("ExternalId"))
+		require.Equal(t, "900", r.PostForm.Get("DurationSeconds"))
+
+		authorization := r.Header.Get("Authorization")
+		require.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDSOURCE/"))
+		require.Contains(t, authorization, "/eu-west-1/sts/aws4_request")
+		require.Equal(t, "SOURCETOKEN", r.Header.Get("X-Amz-Security-Token"))
+	})
+
+	provider := NewAssumeRoleProvider(
+		NewStaticProvider("AKIDSOURCE", "SOURCESECRET", "SOURCETOKEN"),
+		"arn:aws:iam::123456789012:role/demo",
+	)
+	provider.HTTPClient = server.Client()
+	provider.Endpoint = server.URL
+	provider.Region = "eu-west-1"
+	provider.RoleSessionName = "session"
+	provider.ExternalID = "external"
+	provider.Duration = 15 * time.Minute
+
+	creds, err := provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, stsExpected, creds)
+}
+
+func TestAssumeRoleProviderErrors(t *testing.T) {
+	server := newSTSServer(t, func(t *testing.T, r *http.Request) {})
+
+	t.Run("Denied", func(t *testing.T) {
+		provider := NewAssumeRoleProvider(NewStaticProvider("A", "S", ""), "arn:aws:iam::123456789012:role/denied")
+		provider.HTTPClient = server.Client()
+		provider.Endpoint = server.URL
+
+		_, err := provider.Retrieve(context.Background())
+
+		var stsErr *STSError
+		require.ErrorAs(t, err, &stsErr)
+		require.Equal(t, http.StatusForbidden, stsErr.StatusCode)
+		require.Equal(t, "AccessDenied", stsErr.Code)
+		require.Equal(t, "d2b5e3b1-af31-11e0-8154-cbc7ccf896c7", stsErr.RequestID)
+	})
+
+	t.Run("NoSource", func(t *testing.T) {
+		provider := NewAssumeRoleProvider(NewChainProvider(), "arn:aws:iam::123456789012:role/demo")
+		provider.Endpoint = server.URL
+
+		_, err := provider.Retrieve(context.Background())
+		require.ErrorIs(t, err, ErrCredentialsNotFound)
+	})
+}
+
+func TestWebIdentityProvider(t *testing.T) {
+	server := newSTSServer(t, func(t *testing.T, r *http.Request) {
+		require.Equal(t, "AssumeRoleWithWebIdentity", r.PostForm.Get("Action"))
+		require.Equal(t, "arn:aws:iam::123456789012:role/demo", r.PostForm.Get("RoleArn"))
+		require.Equal(t, "projected-token", r.PostForm.Get("WebIdentityToken"))
+		require.True(t, strings.HasPrefix(r.PostForm.Get("RoleSessionName"), "raw-s3-sdk-go-"))
+		require.Empty(t, r.Header.Get("Authorization"))
+	})
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("projected-token\n"), 0o600))
+
+	provider := NewWebIdentityProvider("arn:aws:iam::123456789012:role/demo", tokenFile)
+	provider.HTTPClient = server.Client()
+	provider.Endpoint = server.URL
+
+	creds, err := provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, stsExpected, creds)
+
+	t.Run("MissingToken", func(t *testing.T) {
+		provider := NewWebIdentityProvider("arn:aws:iam::123456789012:role/demo", filepath.Join(t.TempDir(), "missing"))
+		provider.Endpoint = server.URL
+
+		_, err := provider.Retrieve(context.Background())
+		require.ErrorContains(t, err, "web identity token")
+	})
+}
+
+func TestWebIdentityProviderCached(t *testing.T) {
+	calls := 0
+	server := newSTSServer(t, func(t *testing.T, r *http.Request) { calls++ })
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("projected-token"), 0o600))
+
+	provider := NewWebIdentityProvider("arn:aws:iam::123456789012:role/demo", tokenFile)
+	provider.HTTPClient = server.Client()
+	provider.Endpoint = server.URL
+
+	cache := NewCache(provider, DefaultExpiryWindow)
+	cache.now = func() time.Time { return stsExpected.Expires.Add(-time.Hour) }
+
+	for i := 0; i < 3; i++ {
+		_, err := cache.Retrieve(context.Background())
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, calls)
+
+	cache.now = func() time.Time { return stsExpected.Expires.Add(-time.Minute) }
+	_, err := cache.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+}
@@ -0,0 +1,111 @@
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+)
+
+// Checkpoint is the state of a resumable upload, persisted as JSON after each
+// uploaded part.
+type Checkpoint struct {
+	Bucket   string `json:"bucket"`
+	Key      string `json:"key"`
+	UploadID string `json:"uploadId"`
+
+	// Size is the length of the body, to detect a changed source.
+	Size     int64 `json:"size"`
+	PartSize int64 `json:"partSize"`
+
+	Parts []CheckpointPart `json:"parts"`
+}
+
+type CheckpointPart struct {
+	PartNumber int32  `json:"partNumber"`
+	ETag       string `json:"etag"`
+	Size       int64  `json:"size"`
+
+	// MD5 is the hex-encoded MD5 of the part, sent as its Content-MD5. On
+	// resume, the part is uploaded again when the body no longer matches it.
+	MD5 string `json:"md5"`
+}
+
+// LoadCheckpoint reads a checkpoint file. It returns nil without error when
+// the file does not exist.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil //nolint:nilnil // a missing checkpoint is not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the checkpoint: %w", err)
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(raw, &checkpoint); err != nil {
+		return nil, fmt.Errorf("cannot parse the checkpoint %s: %w", path, err)
+	}
+
+	if err := checkpoint.validate(); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+
+	return &checkpoint, nil
+}
+
+// validate rejects the checkpoints whose upload cannot be resumed, before
+// their part size is used to split the body.
+func (c *Checkpoint) validate() error {
+	if c.UploadID == "" {
+		return errors.New("upload ID is empty")
+	}
+
+	if c.Size < 0 {
+		return fmt.Errorf("size %d is negative", c.Size)
+	}
+
+	if c.PartSize <= 0 {
+		return fmt.Errorf("part size %d must be positive", c.PartSize)
+	}
+
+	if count := (c.Size + c.PartSize - 1) / c.PartSize; count > MaxUploadParts {
+		return fmt.Errorf("%d bytes exceed %d parts of %d bytes", c.Size, MaxUploadParts, c.PartSize)
+	}
+
+	return nil
+}
+
+// Save writes the checkpoint to a temporary file renamed over path, so that a
+// crash never leaves a truncated checkpoint.
+func (c *Checkpoint) Save(path string) error {
+	sort.Slice(c.Parts, func(i, j int) bool { return c.Parts[i].PartNumber < c.Parts[j].PartNumber })
+
+	raw, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode the checkpoint: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("cannot write the checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("cannot write the checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Checkpoint) part(number int32) *CheckpointPart {
+	for i := range c.Parts {
+		if c.Parts[i].PartNumber == number {
+			return &c.Parts[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,436 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lvjp/raw-s3-sdk-go/service"
+)
+
+const (
+	DefaultDownloadPartSize    = MinUploadPartSize
+	DefaultDownloadConcurrency = 5
+
+	// DefaultDownloadPartRetries is the number of times a failed range is
+	// retried.
+	DefaultDownloadPartRetries = 3
+)
+
+// DownloadAPIClient is the subset of the service.Service operations used by
+// the Downloader.
+type DownloadAPIClient interface {
+	GetObject(context.Context, *service.GetObjectInput, ...service.Option) (*service.GetObjectOutput, error)
+}
+
+var _ DownloadAPIClient = (*service.Service)(nil)
+
+// Downloader fetches objects with concurrent ranged GETs. The ETag of the
+// first response is pinned with If-Match on the next ones: an object
+// overwritten during the download fails it with service.ErrPreconditionFailed.
+type Downloader struct {
+	Client DownloadAPIClient
+
+	// PartSize is the size of each ranged GET.
+	PartSize int64
+
+	// Concurrency is the number of GETs in flight at the same time.
+	Concurrency int
+
+	// PartRetries is the number of times a failed GET is retried. Ranges
+	// resume after the bytes already written.
+	PartRetries int
+
+	// UseObjectParts fetches the object part by part, with the partNumber
+	// parameter, instead of by ranges of PartSize.
+	UseObjectParts bool
+
+	// ClientOptions are applied to every call made to the Client.
+	ClientOptions []service.Option
+}
+
+func NewDownloader(client DownloadAPIClient, optFns ...func(*Downloader)) *Downloader {
+	d := &Downloader{
+		Client:      client,
+		PartSize:    DefaultDownloadPartSize,
+		Concurrency: DefaultDownloadConcurrency,
+		PartRetries: DefaultDownloadPartRetries,
+	}
+
+	for _, fn := range optFns {
+		fn(d)
+	}
+
+	return d
+}
+
+type DownloadInput struct {
+	Bucket string
+	Key    string
+
+	// IfMatch pins the ETag from the first GET, instead of the one it returns.
+	IfMatch *string
+}
+
+type DownloadOutput struct {
+	ETag          *string
+	ContentLength int64
+
+	// PartCount is the number of GETs the object was fetched with, retries
+	// excluded.
+	PartCount int
+}
+
+// Download writes the object to w, at the offsets of the object.
+func (d *Downloader) Download(ctx context.Context, w io.WriterAt, input *DownloadInput, optFns ...func(*Downloader)) (*DownloadOutput, error) {
+	cfg := *d
+	for _, fn := range optFns {
+		fn(&cfg)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	download := &downloader{
+		cfg:   &cfg,
+		input: input,
+		w:     w,
+	}
+
+	return download.run(ctx)
+}
+
+// DownloadSequential writes the object to w in order, for destinations that
+// cannot seek like pipes. The ranges are fetched one at a time and the object
+// parts are ignored.
+func (d *Downloader) DownloadSequential(ctx context.Context, w io.Writer, input *DownloadInput, optFns ...func(*Downloader)) (*DownloadOutput, error) {
+	return d.Download(ctx, &sequentialWriterAt{w: w}, input, append(optFns, func(d *Downloader) {
+		d.Concurrency = 1
+		d.UseObjectParts = false
+	})...)
+}
+
+func (d *Downloader) validate() error {
+	if d.Client == nil {
+		return errors.New("downloader client is required")
+	}
+
+	if d.PartSize < 1 {
+		return fmt.Errorf("part size %d must be positive", d.PartSize)
+	}
+
+	if d.Concurrency < 1 {
+		return fmt.Errorf("concurrency %d must be at least 1", d.Concurrency)
+	}
+
+	if d.PartRetries < 0 {
+		return fmt.Errorf("part retries %d must not be negative", d.PartRetries)
+	}
+
+	return nil
+}
+
+// sequentialWriterAt accepts writes at the end of what was already written.
+type sequentialWriterAt struct {
+	w       io.Writer
+	written int64
+}
+
+func (s *sequentialWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	if off != s.written {
+		return 0, fmt.Errorf("sequential write at offset %d, expected %d", off, s.written)
+	}
+
+	n, err := s.w.Write(p)
+	s.written += int64(n)
+
+	return n, err
+}
+
+// chunk is a single GET of the download: either the inclusive byte range from
+// start to end when ranged, or the object part number. A zero chunk fetches
+// the whole object.
+type chunk struct {
+	ranged     bool
+	start, end int64
+	partNumber int32
+}
+
+// chunkResult describes the content returned for a chunk.
+type chunkResult struct {
+	start, end int64
+	total      int64
+	partsCount int32
+	etag       *string
+}
+
+// downloader holds the state of a single Download call.
+type downloader struct {
+	cfg   *Downloader
+	input *DownloadInput
+	w     io.WriterAt
+}
+
+func (d *downloader) run(ctx context.Context) (*DownloadOutput, error) {
+	first := chunk{ranged: true, end: d.cfg.PartSize - 1}
+	if d.cfg.UseObjectParts {
+		first = chunk{partNumber: 1}
+	}
+
+	etag := d.input.IfMatch
+
+	result, err := d.fetch(ctx, first, etag)
+	if errors.Is(err, service.ErrInvalidRange) {
+		// The range starts after the end of an empty object.
+		result, err = d.fetch(ctx, chunk{}, etag)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The pin is only read by the workers. It stays nil when the gateway
+	// returns no ETag.
+	if etag == nil {
+		etag = result.etag
+	}
+
+	var chunks []chunk
+	if d.cfg.UseObjectParts {
+		for number := int32(2); number <= result.partsCount; number++ {
+			chunks = append(chunks, chunk{partNumber: number})
+		}
+	} else {
+		for start := result.end + 1; start < result.total; start += d.cfg.PartSize {
+			chunks = append(chunks, chunk{ranged: true, start: start, end: min64(start+d.cfg.PartSize, result.total) - 1})
+		}
+	}
+
+	if err := d.fetchAll(ctx, chunks, etag); err != nil {
+		return nil, err
+	}
+
+	return &DownloadOutput{
+		ETag:          etag,
+		ContentLength: result.total,
+		PartCount:     len(chunks) + 1,
+	}, nil
+}
+
+// fetchAll downloads the chunks with Concurrency workers, pinned to the etag
+// when not nil. The first error cancels the other GETs.
+func (d *downloader) fetchAll(ctx context.Context, chunks []chunk, etag *string) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		once     sync.Once
+		firstErr error
+	)
+
+	queue := make(chan chunk)
+
+	var wg sync.WaitGroup
+	for i := 0; i < d.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for c := range queue {
+				if _, err := d.fetch(ctx, c, etag); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	for _, c := range chunks {
+		select {
+		case queue <- c:
+			continue
+		case <-ctx.Done():
+		}
+		break
+	}
+
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	return ctx.Err()
+}
+
+// fetch downloads a chunk, retrying up to PartRetries times. A retried range
+// only requests the bytes not written yet, of the object with the ETag of the
+// failed attempt when etag is nil.
+func (d *downloader) fetch(ctx context.Context, c chunk, etag *string) (chunkResult, error) {
+	for attempt := 0; ; attempt++ {
+		result, written, err := d.fetchOnce(ctx, c, etag)
+		if err == nil {
+			return result, nil
+		}
+
+		if etag == nil {
+			etag = result.etag
+		}
+
+		if attempt >= d.cfg.PartRetries || !isRetryable(ctx, err) {
+			return chunkResult{}, err
+		}
+
+		if c.ranged {
+			c.start += written
+		}
+	}
+}
+
+// fetchOnce sends a single GET. The ETag of the response is returned, even
+// when its body fails.
+func (d *downloader) fetchOnce(ctx context.Context, c chunk, etag *string) (chunkResult, int64, error) {
+	input := &service.GetObjectInput{
+		Bucket:  d.input.Bucket,
+		Key:     d.input.Key,
+		IfMatch: etag,
+	}
+
+	switch {
+	case c.partNumber > 0:
+		input.PartNumber = &c.partNumber
+	case c.ranged:
+		rng := fmt.Sprintf("bytes=%d-%d", c.start, c.end)
+		input.Range = &rng
+	}
+
+	output, err := d.cfg.Client.GetObject(ctx, input, d.cfg.ClientOptions...)
+	if err != nil {
+		return chunkResult{}, 0, err
+	}
+	defer output.Body.Close()
+
+	result := chunkResult{
+		end:        output.ContentLength - 1,
+		total:      output.ContentLength,
+		partsCount: 1,
+		etag:       output.ETag,
+	}
+	failed := chunkResult{etag: output.ETag}
+
+	if output.ContentRange != nil {
+		result.start, result.end, result.total, err = parseContentRange(*output.ContentRange)
+		if err != nil {
+			return failed, 0, err
+		}
+	}
+
+	if output.PartsCount != nil {
+		result.partsCount = *output.PartsCount
+	}
+
+	if c.ranged && result.start != c.start {
+		return failed, 0, fmt.Errorf("requested range starting at %d, got %s", c.start, *output.ContentRange)
+	}
+
+	written, err := io.Copy(destination{io.NewOffsetWriter(d.w, result.start)}, output.Body)
+	if err == nil && written != result.end-result.start+1 {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return failed, written, fmt.Errorf("cannot download bytes %d-%d: %w", result.start, result.end, err)
+	}
+
+	return result, written, nil
+}
+
+// parseContentRange parses a "bytes start-end/total" header value.
+func parseContentRange(value string) (start, end, total int64, err error) {
+	invalid := fmt.Errorf("invalid Content-Range %q", value)
+
+	rng, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, 0, invalid
+	}
+
+	rng, totalRaw, found := strings.Cut(rng, "/")
+	if !found {
+		return 0, 0, 0, invalid
+	}
+
+	startRaw, endRaw, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, 0, invalid
+	}
+
+	start, err1 := strconv.ParseInt(startRaw, 10, 64)
+	end, err2 := strconv.ParseInt(endRaw, 10, 64)
+	total, err3 := strconv.ParseInt(totalRaw, 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil || start > end || end >= total {
+		return 0, 0, 0, invalid
+	}
+
+	return start, end, total, nil
+}
+
+// writeError marks the failures of the destination, which are not retried.
+type writeError struct {
+	err error
+}
+
+func (e *writeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *writeError) Unwrap() error {
+	return e.err
+}
+
+type destination struct {
+	w io.Writer
+}
+
+func (d destination) Write(p []byte) (int, error) {
+	n, err := d.w.Write(p)
+	if err != nil {
+		err = &writeError{err: err}
+	}
+
+	return n, err
+}
+
+// isRetryable reports whether the GET may succeed if sent again: transport
+// and body errors, throttling and server errors are.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var respErr *service.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusTooManyRequests || respErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var writeErr *writeError
+	return !errors.As(err, &writeErr)
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,296 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lvjp/raw-s3-sdk-go/service"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeObject serves a single object, by ranges or by parts of partSize.
+type fakeObject struct {
+	t *testing.T
+
+	mu       sync.Mutex
+	content  []byte
+	etag     string
+	partSize int64
+	requests []http.Header
+
+	// truncateAt makes the first response starting at this offset stop
+	// halfway, when positive.
+	truncateAt int64
+
+	// overwriteAfter changes the ETag after this number of requests, when
+	// positive.
+	overwriteAfter int
+
+	// noETag omits the ETag header, as some gateways do.
+	noETag bool
+}
+
+func (f *fakeObject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	request := r.Header.Clone()
+	request.Set("Part-Number", r.URL.Query().Get("partNumber"))
+	f.requests = append(f.requests, request)
+
+	if len(f.requests) == f.overwriteAfter+1 && f.overwriteAfter > 0 {
+		f.etag = `"overwritten"`
+	}
+
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != f.etag {
+		f.writeError(w, http.StatusPreconditionFailed, "PreconditionFailed")
+		return
+	}
+
+	total := int64(len(f.content))
+	start, end := int64(0), total-1
+	partial := false
+
+	if partNumber := r.URL.Query().Get("partNumber"); partNumber != "" {
+		number, err := strconv.ParseInt(partNumber, 10, 64)
+		require.NoError(f.t, err)
+
+		start = (number - 1) * f.partSize
+		end = min64(start+f.partSize, total) - 1
+		partial = true
+		w.Header().Set("X-Amz-Mp-Parts-Count", strconv.FormatInt((total+f.partSize-1)/f.partSize, 10))
+	} else if rng := r.Header.Get("Range"); rng != "" {
+		startRaw, endRaw, found := strings.Cut(strings.TrimPrefix(rng, "bytes="), "-")
+		require.True(f.t, found)
+
+		var err error
+		start, err = strconv.ParseInt(startRaw, 10, 64)
+		require.NoError(f.t, err)
+		end, err = strconv.ParseInt(endRaw, 10, 64)
+		require.NoError(f.t, err)
+
+		if start >= total {
+			f.writeError(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+			return
+		}
+
+		end = min64(end, total-1)
+		partial = true
+	}
+
+	headers := w.Header()
+	if !f.noETag {
+		headers.Set("ETag", f.etag)
+	}
+	headers.Set("Content-Length", strconv.FormatInt(end-start+1, 10))
+
+	status := http.StatusOK
+	if partial {
+		headers.Set("Content-Range", "bytes "+strconv.FormatInt(start, 10)+"-"+strconv.FormatInt(end, 10)+"/"+strconv.FormatInt(total, 10))
+		status = http.StatusPartialContent
+	}
+	w.WriteHeader(status)
+
+	body := f.content[start : end+1]
+	if f.truncateAt > 0 && start == f.truncateAt {
+		f.truncateAt = 0
+		body = body[:len(body)/2]
+	}
+
+	_, _ = w.Write(body)
+}
+
+func (f *fakeObject) writeError(w http.ResponseWriter, status int, code string) {
+	raw, err := xml.Marshal(&types.Error{Code: code})
+	require.NoError(f.t, err)
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write(raw)
+}
+
+// memWriterAt is an in-memory io.WriterAt.
+type memWriterAt struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (m *memWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if end := off + int64(len(p)); end > int64(len(m.buf)) {
+		m.buf = append(m.buf, make([]byte, end-int64(len(m.buf)))...)
+	}
+
+	return copy(m.buf[off:], p), nil
+}
+
+func newFakeObject(t *testing.T, size int64) (*fakeObject, *Downloader) {
+	f := &fakeObject{
+		t:        t,
+		content:  randomBytes(t, size),
+		etag:     `"original"`,
+		partSize: 1000,
+	}
+
+	downloader := NewDownloader(newService(t, f), func(d *Downloader) {
+		d.PartSize = 1000
+		d.Concurrency = 3
+	})
+
+	return f, downloader
+}
+
+func TestDownload(t *testing.T) {
+	fake, downloader := newFakeObject(t, 3500)
+
+	var w memWriterAt
+	output, err := downloader.Download(context.Background(), &w, &DownloadInput{Bucket: "myBucket", Key: "large.bin"})
+	require.NoError(t, err)
+	require.Equal(t, `"original"`, *output.ETag)
+	require.Equal(t, int64(3500), output.ContentLength)
+	require.Equal(t, 4, output.PartCount)
+	require.Equal(t, fake.content, w.buf)
+
+	require.Len(t, fake.requests, 4)
+	require.Equal(t, "bytes=0-999", fake.requests[0].Get("Range"))
+	require.Empty(t, fake.requests[0].Get("If-Match"))
+
+	ranges := []string{}
+	for _, request := range fake.requests[1:] {
+		require.Equal(t, `"original"`, request.Get("If-Match"))
+		ranges = append(ranges, request.Get("Range"))
+	}
+	require.ElementsMatch(t, []string{"bytes=1000-1999", "bytes=2000-2999", "bytes=3000-3499"}, ranges)
+}
+
+func TestDownloadObjectParts(t *testing.T) {
+	fake, downloader := newFakeObject(t, 2500)
+
+	var w memWriterAt
+	output, err := downloader.Download(context.Background(), &w, &DownloadInput{Bucket: "myBucket", Key: "large.bin"}, func(d *Downloader) {
+		d.UseObjectParts = true
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, output.PartCount)
+	require.Equal(t, fake.content, w.buf)
+
+	parts := []string{}
+	for _, request := range fake.requests {
+		require.Empty(t, request.Get("Range"))
+		parts = append(parts, request.Get("Part-Number"))
+	}
+	require.ElementsMatch(t, []string{"1", "2", "3"}, parts)
+}
+
+func TestDownloadRetry(t *testing.T) {
+	fake, downloader := newFakeObject(t, 3000)
+	fake.truncateAt = 1000
+
+	var w memWriterAt
+	_, err := downloader.Download(context.Background(), &w, &DownloadInput{Bucket: "myBucket", Key: "large.bin"})
+	require.NoError(t, err)
+	require.Equal(t, fake.content, w.buf)
+
+	ranges := []string{}
+	for _, request := range fake.requests {
+		ranges = append(ranges, request.Get("Range"))
+	}
+	require.Contains(t, ranges, "bytes=1500-1999")
+
+	t.Run("Exhausted", func(t *testing.T) {
+		fake, downloader := newFakeObject(t, 3000)
+		fake.truncateAt = 1000
+
+		_, err := downloader.Download(context.Background(), &memWriterAt{}, &DownloadInput{Bucket: "myBucket", Key: "large.bin"}, func(d *Downloader) {
+			d.PartRetries = 0
+		})
+		require.ErrorContains(t, err, "bytes 1000-1999")
+	})
+}
+
+func TestDownloadOverwritten(t *testing.T) {
+	fake, downloader := newFakeObject(t, 3000)
+	fake.overwriteAfter = 1
+
+	_, err := downloader.Download(context.Background(), &memWriterAt{}, &DownloadInput{Bucket: "myBucket", Key: "large.bin"})
+	require.ErrorIs(t, err, service.ErrPreconditionFailed)
+}
+
+func TestDownloadWithoutETag(t *testing.T) {
+	fake, downloader := newFakeObject(t, 5500)
+	fake.noETag = true
+	fake.truncateAt = 2000
+
+	var w memWriterAt
+	output, err := downloader.Download(context.Background(), &w, &DownloadInput{Bucket: "myBucket", Key: "large.bin"})
+	require.NoError(t, err)
+	require.Nil(t, output.ETag)
+	require.Equal(t, fake.content, w.buf)
+
+	for _, request := range fake.requests {
+		require.Empty(t, request.Get("If-Match"))
+	}
+}
+
+func TestDownloadPartSizeOne(t *testing.T) {
+	fake, downloader := newFakeObject(t, 3)
+
+	var w memWriterAt
+	output, err := downloader.Download(context.Background(), &w, &DownloadInput{Bucket: "myBucket", Key: "tiny.bin"}, func(d *Downloader) {
+		d.PartSize = 1
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, output.PartCount)
+	require.Equal(t, fake.content, w.buf)
+	require.Equal(t, "bytes=0-0", fake.requests[0].Get("Range"))
+}
+
+func TestDownloadEmpty(t *testing.T) {
+	fake, downloader := newFakeObject(t, 0)
+
+	var w memWriterAt
+	output, err := downloader.Download(context.Background(), &w, &DownloadInput{Bucket: "myBucket", Key: "empty"})
+	require.NoError(t, err)
+	require.Zero(t, output.ContentLength)
+	require.Empty(t, w.buf)
+
+	require.Len(t, fake.requests, 2)
+	require.Empty(t, fake.requests[1].Get("Range"))
+}
+
+func TestDownloadSequential(t *testing.T) {
+	fake, downloader := newFakeObject(t, 3500)
+	fake.truncateAt = 2000
+
+	var w bytes.Buffer
+	output, err := downloader.DownloadSequential(context.Background(), &w, &DownloadInput{Bucket: "myBucket", Key: "large.bin"})
+	require.NoError(t, err)
+	require.Equal(t, int64(3500), output.ContentLength)
+	require.Equal(t, fake.content, w.Bytes())
+
+	ranges := []string{}
+	for _, request := range fake.requests {
+		ranges = append(ranges, request.Get("Range"))
+	}
+	require.Equal(t, []string{"bytes=0-999", "bytes=1000-1999", "bytes=2000-2999", "bytes=2500-2999", "bytes=3000-3499"}, ranges)
+}
+
+func TestParseContentRange(t *testing.T) {
+	start, end, total, err := parseContentRange("bytes 2-5/10")
+	require.NoError(t, err)
+	require.Equal(t, []int64{2, 5, 10}, []int64{start, end, total})
+
+	for _, value := range []string{"", "bytes */10", "bytes 2-5", "bytes 5-2/10", "bytes 2-10/10", "items 2-5/10"} {
+		_, _, _, err := parseContentRange(value)
+		require.Error(t, err, value)
+	}
+}
@@ -0,0 +1,41 @@
+package manager
+
+import "context"
+
+// partPool hands out at most its capacity of part buffers, so that the memory
+// of an upload is bounded whatever the size of the body. Buffers are
+// allocated on first use and recycled afterwards.
+type partPool struct {
+	partSize int64
+	slots    chan []byte
+}
+
+func newPartPool(partSize int64, capacity int) *partPool {
+	p := &partPool{
+		partSize: partSize,
+		slots:    make(chan []byte, capacity),
+	}
+
+	for i := 0; i < capacity; i++ {
+		p.slots <- nil
+	}
+
+	return p
+}
+
+// get waits for a free buffer, or for the context to be done.
+func (p *partPool) get(ctx context.Context) ([]byte, error) {
+	select {
+	case buf := <-p.slots:
+		if buf == nil {
+			buf = make([]byte, p.partSize)
+		}
+		return buf, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *partPool) put(buf []byte) {
+	p.slots <- buf
+}
@@ -0,0 +1,422 @@
+package manager
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // required by Content-MD5 and the part ETags
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/service"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+// ResumableUploadAPIClient is the subset of the service.Service operations
+// used by Uploader.UploadResumable.
+type ResumableUploadAPIClient interface {
+	UploadAPIClient
+	ListParts(context.Context, *service.ListPartsInput, ...service.Option) (*service.ListPartsOutput, error)
+}
+
+var _ ResumableUploadAPIClient = (*service.Service)(nil)
+
+type ResumableUploadInput struct {
+	Bucket string
+	Key    string
+
+	// Body is read at the offsets of the parts, so that any of them can be
+	// sent again.
+	Body io.ReaderAt
+	Size int64
+
+	// CheckpointFile persists the state of the upload. It is removed once the
+	// upload is completed.
+	CheckpointFile string
+
+	CacheControl       *string
+	ContentDisposition *string
+	ContentEncoding    *string
+	ContentLanguage    *string
+	ContentType        *string
+	Expires            *time.Time
+	StorageClass       *string
+
+	Metadata map[string]string
+}
+
+// UploadResumable sends the body with a multipart upload whose state is saved
+// to the checkpoint file after each part. When the file exists, the upload it
+// describes is resumed: it is reconciled with ListParts and only the missing
+// parts are sent. A failed upload is never aborted, so that it can be resumed.
+func (u *Uploader) UploadResumable(ctx context.Context, input *ResumableUploadInput, optFns ...func(*Uploader)) (*UploadOutput, error) {
+	cfg := *u
+	for _, fn := range optFns {
+		fn(&cfg)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	client, ok := cfg.Client.(ResumableUploadAPIClient)
+	if !ok {
+		return nil, errors.New("uploader client does not support ListParts")
+	}
+
+	checkpoint, err := LoadCheckpoint(input.CheckpointFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkpoint != nil && (checkpoint.Bucket != input.Bucket || checkpoint.Key != input.Key || checkpoint.Size != input.Size) {
+		return nil, fmt.Errorf(
+			"checkpoint %s is for %d bytes to %s/%s",
+			input.CheckpointFile, checkpoint.Size, checkpoint.Bucket, checkpoint.Key,
+		)
+	}
+
+	upload := &resumableUpload{
+		cfg:        &cfg,
+		client:     client,
+		input:      input,
+		checkpoint: checkpoint,
+	}
+	upload.progress.TotalBytes = input.Size
+
+	return upload.run(ctx)
+}
+
+// resumableUpload holds the state of a single UploadResumable call.
+type resumableUpload struct {
+	cfg    *Uploader
+	client ResumableUploadAPIClient
+	input  *ResumableUploadInput
+
+	// mu guards the checkpoint and the progress, updated by the workers.
+	mu         sync.Mutex
+	checkpoint *Checkpoint
+	progress   UploadProgress
+}
+
+func (u *resumableUpload) run(ctx context.Context) (*UploadOutput, error) {
+	if u.checkpoint != nil {
+		if err := u.reconcile(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if u.checkpoint == nil {
+		if err := u.create(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	uploadID := u.checkpoint.UploadID
+	partCount := u.partCount()
+
+	var missing []int32
+	for number := int32(1); number <= partCount; number++ {
+		if part := u.checkpoint.part(number); part != nil {
+			u.reportProgress(part.Size)
+		} else {
+			missing = append(missing, number)
+		}
+	}
+
+	if err := u.uploadParts(ctx, missing); err != nil {
+		return nil, &MultipartUploadError{UploadID: uploadID, Err: err}
+	}
+
+	parts := make([]types.CompletedPart, 0, len(u.checkpoint.Parts))
+	for _, part := range u.checkpoint.Parts {
+		etag := part.ETag
+		parts = append(parts, types.CompletedPart{ETag: &etag, PartNumber: part.PartNumber})
+	}
+
+	completed, err := u.client.CompleteMultipartUpload(ctx, &service.CompleteMultipartUploadInput{
+		Bucket:   u.input.Bucket,
+		Key:      u.input.Key,
+		UploadID: uploadID,
+		Parts:    parts,
+	}, u.cfg.ClientOptions...)
+	if err != nil {
+		return nil, &MultipartUploadError{UploadID: uploadID, Err: err}
+	}
+
+	if err := os.Remove(u.input.CheckpointFile); err != nil {
+		return nil, fmt.Errorf("cannot remove the checkpoint of the completed upload: %w", err)
+	}
+
+	return &UploadOutput{
+		ETag:      completed.Payload.ETag,
+		Location:  completed.Payload.Location,
+		UploadID:  &uploadID,
+		PartCount: len(parts),
+	}, nil
+}
+
+func (u *resumableUpload) create(ctx context.Context) error {
+	partSize := u.cfg.PartSize
+	if (u.input.Size+partSize-1)/partSize > MaxUploadParts {
+		partSize = (u.input.Size + MaxUploadParts - 1) / MaxUploadParts
+	}
+
+	created, err := u.client.CreateMultipartUpload(ctx, &service.CreateMultipartUploadInput{
+		Bucket:             u.input.Bucket,
+		Key:                u.input.Key,
+		CacheControl:       u.input.CacheControl,
+		ContentDisposition: u.input.ContentDisposition,
+		ContentEncoding:    u.input.ContentEncoding,
+		ContentLanguage:    u.input.ContentLanguage,
+		ContentType:        u.input.ContentType,
+		Expires:            u.input.Expires,
+		StorageClass:       u.input.StorageClass,
+		Metadata:           u.input.Metadata,
+	}, u.cfg.ClientOptions...)
+	if err != nil {
+		return err
+	}
+
+	if created.Payload.UploadID == nil {
+		return errors.New("CreateMultipartUpload response misses the upload id")
+	}
+
+	u.checkpoint = &Checkpoint{
+		Bucket:   u.input.Bucket,
+		Key:      u.input.Key,
+		UploadID: *created.Payload.UploadID,
+		Size:     u.input.Size,
+		PartSize: partSize,
+	}
+
+	return u.checkpoint.Save(u.input.CheckpointFile)
+}
+
+// reconcile keeps the checkpointed parts S3 still lists with the same ETag and
+// whose MD5 still matches the body. It adopts the listed parts missing from
+// the checkpoint whose MD5 matches the body, as when a crash happened before
+// the checkpoint was saved. The checkpoint is dropped when S3 no longer knows
+// the upload.
+func (u *resumableUpload) reconcile(ctx context.Context) error {
+	listed, err := u.listParts(ctx)
+	if errors.Is(err, service.ErrNoSuchUpload) {
+		u.checkpoint = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var parts []CheckpointPart
+	for _, remote := range listed {
+		if remote.ETag == nil || remote.Size != u.partSize(remote.PartNumber) {
+			continue
+		}
+
+		sum, err := u.partMD5(remote.PartNumber)
+		if err != nil {
+			return err
+		}
+		md5Hex := hex.EncodeToString(sum)
+
+		// The body may have been edited in place since the part was sent:
+		// a part is only kept when it still has the content uploaded.
+		if local := u.checkpoint.part(remote.PartNumber); local != nil {
+			if local.ETag == *remote.ETag && local.MD5 == md5Hex {
+				parts = append(parts, *local)
+			}
+			continue
+		}
+
+		if strconv.Quote(md5Hex) == *remote.ETag {
+			parts = append(parts, CheckpointPart{
+				PartNumber: remote.PartNumber,
+				ETag:       *remote.ETag,
+				Size:       remote.Size,
+				MD5:        md5Hex,
+			})
+		}
+	}
+
+	u.checkpoint.Parts = parts
+
+	return u.checkpoint.Save(u.input.CheckpointFile)
+}
+
+func (u *resumableUpload) listParts(ctx context.Context) ([]types.Part, error) {
+	var (
+		parts  []types.Part
+		marker *string
+	)
+
+	for {
+		output, err := u.client.ListParts(ctx, &service.ListPartsInput{
+			Bucket:           u.input.Bucket,
+			Key:              u.input.Key,
+			UploadID:         u.checkpoint.UploadID,
+			PartNumberMarker: marker,
+		}, u.cfg.ClientOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, output.Payload.Parts...)
+
+		if !output.Payload.IsTruncated || output.Payload.NextPartNumberMarker == nil {
+			return parts, nil
+		}
+		marker = output.Payload.NextPartNumberMarker
+	}
+}
+
+// uploadParts sends the parts with Concurrency workers. The first error
+// cancels the other uploads.
+func (u *resumableUpload) uploadParts(ctx context.Context, numbers []int32) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		once     sync.Once
+		firstErr error
+	)
+
+	queue := make(chan int32)
+
+	var wg sync.WaitGroup
+	for i := 0; i < u.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for number := range queue {
+				if err := u.uploadPart(ctx, number); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	for _, number := range numbers {
+		select {
+		case queue <- number:
+			continue
+		case <-ctx.Done():
+		}
+		break
+	}
+
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	return ctx.Err()
+}
+
+func (u *resumableUpload) uploadPart(ctx context.Context, number int32) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sum, err := u.partMD5(number)
+	if err != nil {
+		return err
+	}
+
+	size := u.partSize(number)
+
+	output, err := u.client.UploadPart(ctx, &service.UploadPartInput{
+		Bucket:        u.input.Bucket,
+		Key:           u.input.Key,
+		UploadID:      u.checkpoint.UploadID,
+		PartNumber:    number,
+		Body:          u.partReader(number),
+		ContentLength: &size,
+		ContentMD5:    stringPtr(base64.StdEncoding.EncodeToString(sum)),
+	}, u.cfg.ClientOptions...)
+	if err != nil {
+		return fmt.Errorf("cannot upload part %d: %w", number, err)
+	}
+
+	if output.ETag == nil {
+		return fmt.Errorf("UploadPart response of part %d misses the ETag", number)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.checkpoint.Parts = append(u.checkpoint.Parts, CheckpointPart{
+		PartNumber: number,
+		ETag:       *output.ETag,
+		Size:       size,
+		MD5:        hex.EncodeToString(sum),
+	})
+
+	if err := u.checkpoint.Save(u.input.CheckpointFile); err != nil {
+		return err
+	}
+
+	u.reportProgressLocked(size)
+
+	return nil
+}
+
+func (u *resumableUpload) partCount() int32 {
+	count := (u.input.Size + u.checkpoint.PartSize - 1) / u.checkpoint.PartSize
+	if count == 0 {
+		// An empty body is uploaded as a single empty part.
+		count = 1
+	}
+
+	return int32(count)
+}
+
+func (u *resumableUpload) partSize(number int32) int64 {
+	start := int64(number-1) * u.checkpoint.PartSize
+	return min64(u.checkpoint.PartSize, u.input.Size-start)
+}
+
+func (u *resumableUpload) partReader(number int32) *io.SectionReader {
+	return io.NewSectionReader(u.input.Body, int64(number-1)*u.checkpoint.PartSize, u.partSize(number))
+}
+
+func (u *resumableUpload) partMD5(number int32) ([]byte, error) {
+	hash := md5.New() //nolint:gosec // required by Content-MD5 and the part ETags
+	if _, err := io.Copy(hash, u.partReader(number)); err != nil {
+		return nil, fmt.Errorf("cannot read part %d of the body: %w", number, err)
+	}
+
+	return hash.Sum(nil), nil
+}
+
+func (u *resumableUpload) reportProgress(size int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.reportProgressLocked(size)
+}
+
+func (u *resumableUpload) reportProgressLocked(size int64) {
+	if u.cfg.OnProgress == nil {
+		return
+	}
+
+	u.progress.BytesUploaded += size
+	u.progress.PartsUploaded++
+	u.cfg.OnProgress(u.progress)
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
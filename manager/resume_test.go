@@ -0,0 +1,261 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newResumableInput(t *testing.T, content []byte) *ResumableUploadInput {
+	return &ResumableUploadInput{
+		Bucket:         "myBucket",
+		Key:            "large.bin",
+		Body:           bytes.NewReader(content),
+		Size:           int64(len(content)),
+		CheckpointFile: filepath.Join(t.TempDir(), "upload.checkpoint"),
+	}
+}
+
+func TestUploadResumable(t *testing.T) {
+	fake, svc := newFakeS3(t)
+	content := randomBytes(t, 2*MinUploadPartSize+42)
+	input := newResumableInput(t, content)
+
+	var last UploadProgress
+	output, err := NewUploader(svc).UploadResumable(context.Background(), input, func(u *Uploader) {
+		u.OnProgress = func(p UploadProgress) { last = p }
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, output.PartCount)
+	require.Equal(t, content, fake.objects["/myBucket/large.bin"])
+	require.Equal(t, UploadProgress{BytesUploaded: int64(len(content)), PartsUploaded: 3, TotalBytes: int64(len(content))}, last)
+
+	require.NoFileExists(t, input.CheckpointFile)
+	require.Zero(t, fake.count("ListParts"))
+}
+
+func TestUploadResumableResume(t *testing.T) {
+	fake, svc := newFakeS3(t)
+	content := randomBytes(t, 4*MinUploadPartSize+42)
+	input := newResumableInput(t, content)
+
+	fake.failPart = 3
+	uploader := NewUploader(svc, func(u *Uploader) { u.Concurrency = 1 })
+
+	_, err := uploader.UploadResumable(context.Background(), input)
+	var uploadErr *MultipartUploadError
+	require.ErrorAs(t, err, &uploadErr)
+	require.Zero(t, fake.count("AbortMultipartUpload"))
+
+	checkpoint, err := LoadCheckpoint(input.CheckpointFile)
+	require.NoError(t, err)
+	require.Equal(t, uploadErr.UploadID, checkpoint.UploadID)
+	require.Equal(t, MinUploadPartSize, checkpoint.PartSize)
+	require.Len(t, checkpoint.Parts, 2)
+	require.Equal(t, partETag(content[:MinUploadPartSize]), checkpoint.Parts[0].ETag)
+
+	fake.failPart = 0
+	uploads := fake.count("UploadPart")
+
+	output, err := uploader.UploadResumable(context.Background(), input)
+	require.NoError(t, err)
+	require.Equal(t, uploadErr.UploadID, *output.UploadID)
+	require.Equal(t, 5, output.PartCount)
+	require.Equal(t, content, fake.objects["/myBucket/large.bin"])
+
+	require.Equal(t, 3, fake.count("UploadPart")-uploads)
+	require.Equal(t, 1, fake.count("CreateMultipartUpload"))
+	require.NoFileExists(t, input.CheckpointFile)
+}
+
+func TestUploadResumableReconcile(t *testing.T) {
+	fake, svc := newFakeS3(t)
+	content := randomBytes(t, 4*MinUploadPartSize)
+	input := newResumableInput(t, content)
+
+	fake.failPart = 4
+	uploader := NewUploader(svc, func(u *Uploader) { u.Concurrency = 1 })
+
+	_, err := uploader.UploadResumable(context.Background(), input)
+	require.Error(t, err)
+
+	// Forget part 2 as after a crash before the checkpoint was saved, and
+	// corrupt the ETag of part 3.
+	checkpoint, err := LoadCheckpoint(input.CheckpointFile)
+	require.NoError(t, err)
+	require.Len(t, checkpoint.Parts, 3)
+	checkpoint.Parts = []CheckpointPart{checkpoint.Parts[0], checkpoint.Parts[2]}
+	checkpoint.Parts[1].ETag = `"stale"`
+	require.NoError(t, checkpoint.Save(input.CheckpointFile))
+
+	fake.failPart = 0
+	uploads := fake.count("UploadPart")
+
+	_, err = uploader.UploadResumable(context.Background(), input)
+	require.NoError(t, err)
+	require.Equal(t, content, fake.objects["/myBucket/large.bin"])
+
+	// Part 2 is adopted, parts 3 and 4 are sent.
+	require.Equal(t, 2, fake.count("UploadPart")-uploads)
+	require.Equal(t, 2, fake.count("ListParts"))
+}
+
+func TestUploadResumableBodyChanged(t *testing.T) {
+	fake, svc := newFakeS3(t)
+	content := randomBytes(t, 4*MinUploadPartSize+42)
+	input := newResumableInput(t, content)
+
+	fake.failPart = 3
+	uploader := NewUploader(svc, func(u *Uploader) { u.Concurrency = 1 })
+
+	_, err := uploader.UploadResumable(context.Background(), input)
+	require.Error(t, err)
+
+	// Edit the file in place, keeping its size, within the uploaded part 1.
+	copy(content[10:], "edited in place")
+
+	fake.failPart = 0
+	uploads := fake.count("UploadPart")
+
+	_, err = uploader.UploadResumable(context.Background(), input)
+	require.NoError(t, err)
+	require.Equal(t, content, fake.objects["/myBucket/large.bin"])
+
+	// Part 1 is sent again with the new content, part 2 is kept.
+	require.Equal(t, 4, fake.count("UploadPart")-uploads)
+}
+
+func TestUploadResumableExpiredUpload(t *testing.T) {
+	fake, svc := newFakeS3(t)
+	content := randomBytes(t, MinUploadPartSize+1)
+	input := newResumableInput(t, content)
+
+	require.NoError(t, (&Checkpoint{
+		Bucket:   input.Bucket,
+		Key:      input.Key,
+		UploadID: "expired",
+		Size:     input.Size,
+		PartSize: MinUploadPartSize,
+		Parts:    []CheckpointPart{{PartNumber: 1, ETag: `"gone"`, Size: MinUploadPartSize}},
+	}).Save(input.CheckpointFile))
+
+	output, err := NewUploader(svc).UploadResumable(context.Background(), input)
+	require.NoError(t, err)
+	require.Equal(t, "upload-1", *output.UploadID)
+	require.Equal(t, content, fake.objects["/myBucket/large.bin"])
+	require.Equal(t, 2, fake.count("UploadPart"))
+}
+
+func TestUploadResumableMismatch(t *testing.T) {
+	_, svc := newFakeS3(t)
+	input := newResumableInput(t, []byte("content"))
+
+	require.NoError(t, (&Checkpoint{
+		Bucket:   input.Bucket,
+		Key:      "other.bin",
+		UploadID: "upload",
+		Size:     input.Size,
+		PartSize: MinUploadPartSize,
+	}).Save(input.CheckpointFile))
+
+	_, err := NewUploader(svc).UploadResumable(context.Background(), input)
+	require.ErrorContains(t, err, "myBucket/other.bin")
+}
+
+func TestUploadResumableEmpty(t *testing.T) {
+	fake, svc := newFakeS3(t)
+	input := newResumableInput(t, nil)
+
+	output, err := NewUploader(svc).UploadResumable(context.Background(), input)
+	require.NoError(t, err)
+	require.Equal(t, 1, output.PartCount)
+	require.Empty(t, fake.objects["/myBucket/large.bin"])
+}
+
+func TestLoadCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+
+	checkpoint, err := LoadCheckpoint(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	require.Nil(t, checkpoint)
+
+	invalid := filepath.Join(dir, "invalid")
+	require.NoError(t, os.WriteFile(invalid, []byte("{"), 0o600))
+	_, err = LoadCheckpoint(invalid)
+	require.ErrorContains(t, err, "cannot parse")
+
+	path := filepath.Join(dir, "checkpoint")
+	expected := &Checkpoint{
+		Bucket:   "myBucket",
+		Key:      "large.bin",
+		UploadID: "upload",
+		Size:     42,
+		PartSize: MinUploadPartSize,
+		Parts: []CheckpointPart{
+			{PartNumber: 2, ETag: `"b"`, Size: 2, MD5: "bb"},
+			{PartNumber: 1, ETag: `"a"`, Size: 1, MD5: "aa"},
+		},
+	}
+	require.NoError(t, expected.Save(path))
+	require.NoFileExists(t, path+".tmp")
+
+	checkpoint, err = LoadCheckpoint(path)
+	require.NoError(t, err)
+	require.Equal(t, expected, checkpoint)
+	require.Equal(t, int32(1), checkpoint.Parts[0].PartNumber)
+}
+
+func TestLoadCheckpointInvalid(t *testing.T) {
+	valid := Checkpoint{
+		Bucket:   "myBucket",
+		Key:      "large.bin",
+		UploadID: "upload",
+		Size:     42,
+		PartSize: MinUploadPartSize,
+	}
+
+	testCases := []struct {
+		name     string
+		modify   func(*Checkpoint)
+		expected string
+	}{
+		{name: "EmptyUploadID", modify: func(c *Checkpoint) { c.UploadID = "" }, expected: "upload ID is empty"},
+		{name: "NegativeSize", modify: func(c *Checkpoint) { c.Size = -1 }, expected: "size -1 is negative"},
+		{name: "ZeroPartSize", modify: func(c *Checkpoint) { c.PartSize = 0 }, expected: "part size 0 must be positive"},
+		{name: "NegativePartSize", modify: func(c *Checkpoint) { c.PartSize = -5 }, expected: "part size -5 must be positive"},
+		{name: "TooManyParts", modify: func(c *Checkpoint) { c.Size = MaxUploadParts + 1; c.PartSize = 1 }, expected: "exceed 10000 parts"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "checkpoint")
+			checkpoint := valid
+			tc.modify(&checkpoint)
+			require.NoError(t, checkpoint.Save(path))
+
+			_, err := LoadCheckpoint(path)
+			require.ErrorContains(t, err, "invalid checkpoint "+path)
+			require.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func TestUploadResumableInvalidCheckpoint(t *testing.T) {
+	fake, svc := newFakeS3(t)
+	input := newResumableInput(t, []byte("content"))
+
+	require.NoError(t, (&Checkpoint{
+		Bucket:   input.Bucket,
+		Key:      input.Key,
+		UploadID: "upload",
+		Size:     input.Size,
+	}).Save(input.CheckpointFile))
+
+	_, err := NewUploader(svc).UploadResumable(context.Background(), input)
+	require.ErrorContains(t, err, "part size 0 must be positive")
+	require.Zero(t, fake.count("UploadPart"))
+}
@@ -0,0 +1,507 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/service"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+const (
+	// MinUploadPartSize is the smallest part S3 accepts, except for the last one.
+	MinUploadPartSize int64 = 5 * 1024 * 1024
+
+	// MaxUploadParts is the maximum number of parts of a multipart upload.
+	MaxUploadParts = 10000
+
+	DefaultUploadPartSize    = MinUploadPartSize
+	DefaultUploadConcurrency = 5
+)
+
+// UploadAPIClient is the subset of the service.Service operations used by
+// the Uploader.
+type UploadAPIClient interface {
+	PutObject(context.Context, *service.PutObjectInput, ...service.Option) (*service.PutObjectOutput, error)
+	CreateMultipartUpload(context.Context, *service.CreateMultipartUploadInput, ...service.Option) (*service.CreateMultipartUploadOutput, error)
+	UploadPart(context.Context, *service.UploadPartInput, ...service.Option) (*service.UploadPartOutput, error)
+	CompleteMultipartUpload(context.Context, *service.CompleteMultipartUploadInput, ...service.Option) (*service.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(context.Context, *service.AbortMultipartUploadInput, ...service.Option) (*service.AbortMultipartUploadOutput, error)
+}
+
+var _ UploadAPIClient = (*service.Service)(nil)
+
+// Uploader sends objects of any size, splitting the large ones into parts
+// uploaded concurrently.
+type Uploader struct {
+	Client UploadAPIClient
+
+	// PartSize is the size of each part but the last one. It is raised when
+	// the body length is known and would need more than MaxUploadParts parts.
+	PartSize int64
+
+	// Concurrency is the number of parts uploaded at the same time. At most
+	// Concurrency+1 parts are held in memory.
+	Concurrency int
+
+	// LeavePartsOnError skips the abort of a failed multipart upload, to
+	// inspect or resume it.
+	LeavePartsOnError bool
+
+	// OnProgress is called after each uploaded part, one call at a time.
+	OnProgress func(UploadProgress)
+
+	// ClientOptions are applied to every call made to the Client.
+	ClientOptions []service.Option
+}
+
+func NewUploader(client UploadAPIClient, optFns ...func(*Uploader)) *Uploader {
+	u := &Uploader{
+		Client:      client,
+		PartSize:    DefaultUploadPartSize,
+		Concurrency: DefaultUploadConcurrency,
+	}
+
+	for _, fn := range optFns {
+		fn(u)
+	}
+
+	return u
+}
+
+// UploadProgress is the state of an upload reported to Uploader.OnProgress.
+type UploadProgress struct {
+	BytesUploaded int64
+	PartsUploaded int
+
+	// TotalBytes is the body length, or -1 when it is unknown.
+	TotalBytes int64
+}
+
+type UploadInput struct {
+	Bucket string
+	Key    string
+
+	// Body is read sequentially until io.EOF. Its length does not need to be
+	// known in advance.
+	Body io.Reader
+
+	CacheControl       *string
+	ContentDisposition *string
+	ContentEncoding    *string
+	ContentLanguage    *string
+	ContentType        *string
+	Expires            *time.Time
+	StorageClass       *string
+
+	Metadata map[string]string
+}
+
+type UploadOutput struct {
+	ETag     *string
+	Location *string
+
+	// UploadID is nil when the object was sent with a single PutObject.
+	UploadID *string
+
+	PartCount int
+}
+
+// MultipartUploadError is returned when a multipart upload fails after its
+// creation. Unless LeavePartsOnError is set, the upload has been aborted.
+type MultipartUploadError struct {
+	UploadID string
+	Err      error
+}
+
+func (e *MultipartUploadError) Error() string {
+	return fmt.Sprintf("multipart upload %s failed: %v", e.UploadID, e.Err)
+}
+
+func (e *MultipartUploadError) Unwrap() error {
+	return e.Err
+}
+
+// Upload sends the body with a single PutObject when it fits in one part, and
+// with a multipart upload otherwise.
+func (u *Uploader) Upload(ctx context.Context, input *UploadInput, optFns ...func(*Uploader)) (*UploadOutput, error) {
+	cfg := *u
+	for _, fn := range optFns {
+		fn(&cfg)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	upload := &uploader{
+		cfg:   &cfg,
+		input: input,
+		total: bodySize(input.Body),
+	}
+
+	return upload.run(ctx)
+}
+
+func (u *Uploader) validate() error {
+	if u.Client == nil {
+		return errors.New("uploader client is required")
+	}
+
+	if u.PartSize < MinUploadPartSize {
+		return fmt.Errorf("part size %d is below the minimum of %d bytes", u.PartSize, MinUploadPartSize)
+	}
+
+	if u.Concurrency < 1 {
+		return fmt.Errorf("concurrency %d must be at least 1", u.Concurrency)
+	}
+
+	return nil
+}
+
+// bodySize returns the length of the in-memory readers and of the seekers, or
+// -1 when it is unknown.
+func bodySize(body io.Reader) int64 {
+	switch v := body.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case io.Seeker:
+		current, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+
+		if _, err := v.Seek(current, io.SeekStart); err != nil {
+			return -1
+		}
+
+		return end - current
+	default:
+		return -1
+	}
+}
+
+// uploader holds the state of a single Upload call.
+type uploader struct {
+	cfg   *Uploader
+	input *UploadInput
+	total int64
+
+	pool *partPool
+
+	progressMu sync.Mutex
+	progress   UploadProgress
+}
+
+// part is a chunk of the body waiting to be uploaded.
+type part struct {
+	number int32
+	buf    []byte
+	size   int
+}
+
+func (u *uploader) run(ctx context.Context) (*UploadOutput, error) {
+	partSize := u.cfg.PartSize
+	if u.total > 0 && (u.total+partSize-1)/partSize > MaxUploadParts {
+		partSize = (u.total + MaxUploadParts - 1) / MaxUploadParts
+	}
+
+	u.pool = newPartPool(partSize, u.cfg.Concurrency+1)
+	u.progress.TotalBytes = u.total
+
+	first, err := u.nextPart(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if first.size < len(first.buf) {
+		return u.putObject(ctx, first)
+	}
+
+	second, err := u.nextPart(ctx, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	if second.size == 0 {
+		u.pool.put(second.buf)
+		return u.putObject(ctx, first)
+	}
+
+	return u.multipart(ctx, first, second)
+}
+
+// nextPart reads the next part of the body. A size smaller than the buffer
+// means the body is exhausted.
+func (u *uploader) nextPart(ctx context.Context, number int32) (part, error) {
+	buf, err := u.pool.get(ctx)
+	if err != nil {
+		return part{}, err
+	}
+
+	n, err := io.ReadFull(u.input.Body, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		u.pool.put(buf)
+		return part{}, fmt.Errorf("cannot read part %d of the body: %w", number, err)
+	}
+
+	return part{number: number, buf: buf, size: n}, nil
+}
+
+func (u *uploader) putObject(ctx context.Context, p part) (*UploadOutput, error) {
+	defer u.pool.put(p.buf)
+
+	contentLength := int64(p.size)
+
+	output, err := u.cfg.Client.PutObject(ctx, &service.PutObjectInput{
+		Bucket:             u.input.Bucket,
+		Key:                u.input.Key,
+		Body:               bytes.NewReader(p.buf[:p.size]),
+		ContentLength:      &contentLength,
+		CacheControl:       u.input.CacheControl,
+		ContentDisposition: u.input.ContentDisposition,
+		ContentEncoding:    u.input.ContentEncoding,
+		ContentLanguage:    u.input.ContentLanguage,
+		ContentType:        u.input.ContentType,
+		Expires:            u.input.Expires,
+		StorageClass:       u.input.StorageClass,
+		Metadata:           u.input.Metadata,
+	}, u.cfg.ClientOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	u.reportProgress(p.size)
+
+	return &UploadOutput{
+		ETag:      output.ETag,
+		PartCount: 1,
+	}, nil
+}
+
+func (u *uploader) multipart(ctx context.Context, first, second part) (*UploadOutput, error) {
+	created, err := u.cfg.Client.CreateMultipartUpload(ctx, &service.CreateMultipartUploadInput{
+		Bucket:             u.input.Bucket,
+		Key:                u.input.Key,
+		CacheControl:       u.input.CacheControl,
+		ContentDisposition: u.input.ContentDisposition,
+		ContentEncoding:    u.input.ContentEncoding,
+		ContentLanguage:    u.input.ContentLanguage,
+		ContentType:        u.input.ContentType,
+		Expires:            u.input.Expires,
+		StorageClass:       u.input.StorageClass,
+		Metadata:           u.input.Metadata,
+	}, u.cfg.ClientOptions...)
+	if err != nil {
+		u.pool.put(first.buf)
+		u.pool.put(second.buf)
+		return nil, err
+	}
+
+	if created.Payload.UploadID == nil {
+		u.pool.put(first.buf)
+		u.pool.put(second.buf)
+		return nil, errors.New("CreateMultipartUpload response misses the upload id")
+	}
+	uploadID := *created.Payload.UploadID
+
+	parts, err := u.uploadParts(ctx, uploadID, first, second)
+	if err != nil {
+		return nil, u.fail(uploadID, err)
+	}
+
+	completed, err := u.cfg.Client.CompleteMultipartUpload(ctx, &service.CompleteMultipartUploadInput{
+		Bucket:   u.input.Bucket,
+		Key:      u.input.Key,
+		UploadID: uploadID,
+		Parts:    parts,
+	}, u.cfg.ClientOptions...)
+	if err != nil {
+		return nil, u.fail(uploadID, err)
+	}
+
+	return &UploadOutput{
+		ETag:      completed.Payload.ETag,
+		Location:  completed.Payload.Location,
+		UploadID:  &uploadID,
+		PartCount: len(parts),
+	}, nil
+}
+
+// uploadParts reads the body and uploads its parts with Concurrency workers.
+// The first error cancels the other uploads and stops the reading.
+func (u *uploader) uploadParts(ctx context.Context, uploadID string, first, second part) ([]types.CompletedPart, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		parts    []types.CompletedPart
+	)
+
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	queue := make(chan part)
+
+	var wg sync.WaitGroup
+	for i := 0; i < u.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for p := range queue {
+				if ctx.Err() != nil {
+					u.pool.put(p.buf)
+					continue
+				}
+
+				completed, err := u.uploadPart(ctx, uploadID, p)
+				if err != nil {
+					setErr(err)
+					continue
+				}
+
+				mu.Lock()
+				parts = append(parts, completed)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	send := func(p part) bool {
+		select {
+		case queue <- p:
+			return true
+		case <-ctx.Done():
+			u.pool.put(p.buf)
+			return false
+		}
+	}
+
+	if send(first) && send(second) {
+		u.readParts(ctx, second, send, setErr)
+	}
+
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+
+	return parts, nil
+}
+
+// readParts reads the parts following last until the body is exhausted and
+// hands them to send. It stops at the first error, reported through setErr,
+// or when send refuses a part.
+func (u *uploader) readParts(ctx context.Context, last part, send func(part) bool, setErr func(error)) {
+	for last.size == len(last.buf) {
+		p, err := u.nextPart(ctx, last.number+1)
+		if err != nil {
+			setErr(err)
+			return
+		}
+
+		if p.size == 0 {
+			u.pool.put(p.buf)
+			return
+		}
+
+		if p.number > MaxUploadParts {
+			u.pool.put(p.buf)
+			setErr(fmt.Errorf("body exceeds %d parts of %d bytes", MaxUploadParts, len(p.buf)))
+			return
+		}
+
+		if !send(p) {
+			return
+		}
+		last = p
+	}
+}
+
+func (u *uploader) uploadPart(ctx context.Context, uploadID string, p part) (types.CompletedPart, error) {
+	defer u.pool.put(p.buf)
+
+	contentLength := int64(p.size)
+
+	output, err := u.cfg.Client.UploadPart(ctx, &service.UploadPartInput{
+		Bucket:        u.input.Bucket,
+		Key:           u.input.Key,
+		UploadID:      uploadID,
+		PartNumber:    p.number,
+		Body:          bytes.NewReader(p.buf[:p.size]),
+		ContentLength: &contentLength,
+	}, u.cfg.ClientOptions...)
+	if err != nil {
+		return types.CompletedPart{}, fmt.Errorf("cannot upload part %d: %w", p.number, err)
+	}
+
+	u.reportProgress(p.size)
+
+	return types.CompletedPart{
+		ETag:       output.ETag,
+		PartNumber: p.number,
+	}, nil
+}
+
+// fail aborts the multipart upload, unless LeavePartsOnError is set. The
+// abort is not bound to the context of the upload, which may be the cause of
+// the failure.
+func (u *uploader) fail(uploadID string, err error) error {
+	if !u.cfg.LeavePartsOnError {
+		_, abortErr := u.cfg.Client.AbortMultipartUpload(context.Background(), &service.AbortMultipartUploadInput{
+			Bucket:   u.input.Bucket,
+			Key:      u.input.Key,
+			UploadID: uploadID,
+		}, u.cfg.ClientOptions...)
+		if abortErr != nil {
+			err = errors.Join(err, fmt.Errorf("cannot abort the upload: %w", abortErr))
+		}
+	}
+
+	return &MultipartUploadError{
+		UploadID: uploadID,
+		Err:      err,
+	}
+}
+
+func (u *uploader) reportProgress(size int) {
+	if u.cfg.OnProgress == nil {
+		return
+	}
+
+	u.progressMu.Lock()
+	defer u.progressMu.Unlock()
+
+	u.progress.BytesUploaded += int64(size)
+	u.progress.PartsUploaded++
+	u.cfg.OnProgress(u.progress)
+}
@@ -0,0 +1,471 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/service"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 keeps the objects and multipart uploads of a single bucket in
+// memory.
+type fakeS3 struct {
+	t *testing.T
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	uploads map[string]map[int][]byte
+	calls   map[string]int
+
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+
+	// failPart makes the upload of this part number fail.
+	failPart int
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *service.Service) {
+	f := &fakeS3{
+		t:       t,
+		objects: map[string][]byte{},
+		uploads: map[string]map[int][]byte{},
+		calls:   map[string]int{},
+	}
+
+	return f, newService(t, f)
+}
+
+func (f *fakeS3) count(operation string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[operation]
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		// The client canceled the request.
+		return
+	}
+
+	if query.Has("partNumber") {
+		defer f.measureConcurrency()()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.createMultipartUpload(w)
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		f.uploadPart(w, r, body)
+	case r.Method == http.MethodGet && query.Has("uploadId"):
+		f.listParts(w, query)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		f.completeMultipartUpload(w, r, body)
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		f.abortMultipartUpload(w, query)
+	case r.Method == http.MethodPut:
+		f.putObject(w, r, body)
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// measureConcurrency records one more part upload in flight and lets the
+// other workers start. The returned function ends the upload.
+func (f *fakeS3) measureConcurrency() func() {
+	current := f.inFlight.Add(1)
+	for {
+		maxValue := f.maxInFlight.Load()
+		if current <= maxValue || f.maxInFlight.CompareAndSwap(maxValue, current) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	return func() { f.inFlight.Add(-1) }
+}
+
+func (f *fakeS3) createMultipartUpload(w http.ResponseWriter) {
+	f.calls["CreateMultipartUpload"]++
+	uploadID := fmt.Sprintf("upload-%d", len(f.uploads)+1)
+	f.uploads[uploadID] = map[int][]byte{}
+	f.writeXML(w, &types.InitiateMultipartUploadResult{UploadID: &uploadID})
+}
+
+func (f *fakeS3) uploadPart(w http.ResponseWriter, r *http.Request, body []byte) {
+	f.calls["UploadPart"]++
+	query := r.URL.Query()
+	number, err := strconv.Atoi(query.Get("partNumber"))
+	require.NoError(f.t, err)
+
+	if number == f.failPart {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if contentMD5 := r.Header.Get("Content-Md5"); contentMD5 != "" {
+		sum := md5.Sum(body)
+		require.Equal(f.t, base64.StdEncoding.EncodeToString(sum[:]), contentMD5)
+	}
+
+	parts, ok := f.uploads[query.Get("uploadId")]
+	if !ok {
+		f.writeError(w, http.StatusNotFound, "NoSuchUpload")
+		return
+	}
+	parts[number] = body
+	w.Header().Set("ETag", partETag(body))
+}
+
+func (f *fakeS3) listParts(w http.ResponseWriter, query url.Values) {
+	f.calls["ListParts"]++
+	parts, ok := f.uploads[query.Get("uploadId")]
+	if !ok {
+		f.writeError(w, http.StatusNotFound, "NoSuchUpload")
+		return
+	}
+	f.writeXML(w, listParts(parts, query))
+}
+
+func (f *fakeS3) completeMultipartUpload(w http.ResponseWriter, r *http.Request, body []byte) {
+	f.calls["CompleteMultipartUpload"]++
+	var payload types.CompleteMultipartUpload
+	require.NoError(f.t, xml.Unmarshal(body, &payload))
+
+	uploadID := r.URL.Query().Get("uploadId")
+	parts := f.uploads[uploadID]
+	require.Len(f.t, payload.Parts, len(parts))
+
+	var object []byte
+	for i, part := range payload.Parts {
+		require.Equal(f.t, int32(i+1), part.PartNumber)
+		require.Equal(f.t, partETag(parts[i+1]), *part.ETag)
+		object = append(object, parts[i+1]...)
+	}
+
+	f.objects[r.URL.Path] = object
+	delete(f.uploads, uploadID)
+	etag := `"multipart"`
+	f.writeXML(w, &types.CompleteMultipartUploadResult{ETag: &etag})
+}
+
+func (f *fakeS3) abortMultipartUpload(w http.ResponseWriter, query url.Values) {
+	f.calls["AbortMultipartUpload"]++
+	delete(f.uploads, query.Get("uploadId"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeS3) putObject(w http.ResponseWriter, r *http.Request, body []byte) {
+	f.calls["PutObject"]++
+	f.objects[r.URL.Path] = body
+	w.Header().Set("ETag", `"single"`)
+}
+
+// listParts pages the parts by two, to exercise the pagination.
+func listParts(parts map[int][]byte, query url.Values) *types.ListPartsResult {
+	marker, _ := strconv.Atoi(query.Get("part-number-marker"))
+
+	numbers := make([]int, 0, len(parts))
+	for number := range parts {
+		if number > marker {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Ints(numbers)
+
+	result := &types.ListPartsResult{MaxParts: 2}
+	if len(numbers) > 2 {
+		numbers = numbers[:2]
+		result.IsTruncated = true
+		next := strconv.Itoa(numbers[1])
+		result.NextPartNumberMarker = &next
+	}
+
+	for _, number := range numbers {
+		etag := partETag(parts[number])
+		result.Parts = append(result.Parts, types.Part{
+			PartNumber: int32(number),
+			ETag:       &etag,
+			Size:       int64(len(parts[number])),
+		})
+	}
+
+	return result
+}
+
+func partETag(body []byte) string {
+	sum := md5.Sum(body)
+	return strconv.Quote(hex.EncodeToString(sum[:]))
+}
+
+func (f *fakeS3) writeError(w http.ResponseWriter, status int, code string) {
+	raw, err := xml.Marshal(&types.Error{Code: code})
+	require.NoError(f.t, err)
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write(raw)
+}
+
+func (f *fakeS3) writeXML(w http.ResponseWriter, payload any) {
+	raw, err := xml.Marshal(payload)
+	require.NoError(f.t, err)
+
+	w.Header().Set("Content-Type", "application/xml")
+	_, err = w.Write(raw)
+	require.NoError(f.t, err)
+}
+
+// onlyReader hides the concrete type of the body, so that its length is
+// unknown.
+type onlyReader struct {
+	io.Reader
+}
+
+func randomBytes(t *testing.T, size int64) []byte {
+	content := make([]byte, size)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+
+	return content
+}
+
+func TestUploadSingle(t *testing.T) {
+	fake, svc := newFakeS3(t)
+	content := randomBytes(t, 1024)
+
+	output, err := NewUploader(svc).Upload(context.Background(), &UploadInput{
+		Bucket: "myBucket",
+		Key:    "small.bin",
+		Body:   onlyReader{bytes.NewReader(content)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `"single"`, *output.ETag)
+	require.Nil(t, output.UploadID)
+	require.Equal(t, 1, output.PartCount)
+
+	require.Equal(t, content, fake.objects["/myBucket/small.bin"])
+	require.Equal(t, 1, fake.count("PutObject"))
+	require.Zero(t, fake.count("CreateMultipartUpload"))
+}
+
+func TestUploadExactlyOnePart(t *testing.T) {
+	fake, svc := newFakeS3(t)
+	content := randomBytes(t, MinUploadPartSize)
+
+	output, err := NewUploader(svc).Upload(context.Background(), &UploadInput{
+		Bucket: "myBucket",
+		Key:    "part.bin",
+		Body:   onlyReader{bytes.NewReader(content)},
+	})
+	require.NoError(t, err)
+	require.Nil(t, output.UploadID)
+	require.Equal(t, content, fake.objects["/myBucket/part.bin"])
+}
+
+func TestUploadMultipart(t *testing.T) {
+	fake, svc := newFakeS3(t)
+	content := randomBytes(t, 5*MinUploadPartSize+42)
+
+	var progress []UploadProgress
+	uploader := NewUploader(svc, func(u *Uploader) {
+		u.Concurrency = 2
+		u.OnProgress = func(p UploadProgress) { progress = append(progress, p) }
+	})
+
+	output, err := uploader.Upload(context.Background(), &UploadInput{
+		Bucket: "myBucket",
+		Key:    "large.bin",
+		Body:   onlyReader{bytes.NewReader(content)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `"multipart"`, *output.ETag)
+	require.Equal(t, "upload-1", *output.UploadID)
+	require.Equal(t, 6, output.PartCount)
+
+	require.Equal(t, content, fake.objects["/myBucket/large.bin"])
+	require.Equal(t, 6, fake.count("UploadPart"))
+	require.Equal(t, int32(2), fake.maxInFlight.Load())
+
+	require.Len(t, progress, 6)
+	require.Equal(t, UploadProgress{
+		BytesUploaded: int64(len(content)),
+		PartsUploaded: 6,
+		TotalBytes:    -1,
+	}, progress[5])
+	require.True(t, sort.SliceIsSorted(progress, func(i, j int) bool {
+		return progress[i].BytesUploaded < progress[j].BytesUploaded
+	}))
+}
+
+func TestUploadKnownLength(t *testing.T) {
+	_, svc := newFakeS3(t)
+	content := randomBytes(t, 2*MinUploadPartSize+1)
+
+	var last UploadProgress
+	_, err := NewUploader(svc).Upload(context.Background(), &UploadInput{
+		Bucket: "myBucket",
+		Key:    "large.bin",
+		Body:   bytes.NewReader(content),
+	}, func(u *Uploader) {
+		u.OnProgress = func(p UploadProgress) { last = p }
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), last.TotalBytes)
+	require.Equal(t, 3, last.PartsUploaded)
+}
+
+func TestUploadAbortOnError(t *testing.T) {
+	fake, svc := newFakeS3(t)
+	fake.failPart = 3
+
+	_, err := NewUploader(svc).Upload(context.Background(), &UploadInput{
+		Bucket: "myBucket",
+		Key:    "large.bin",
+		Body:   onlyReader{bytes.NewReader(randomBytes(t, 4*MinUploadPartSize))},
+	})
+
+	var uploadErr *MultipartUploadError
+	require.ErrorAs(t, err, &uploadErr)
+	require.Equal(t, "upload-1", uploadErr.UploadID)
+
+	var respErr *service.ResponseError
+	require.ErrorAs(t, err, &respErr)
+	require.Equal(t, http.StatusInternalServerError, respErr.StatusCode)
+
+	require.Equal(t, 1, fake.count("AbortMultipartUpload"))
+	require.Zero(t, fake.count("CompleteMultipartUpload"))
+	require.Empty(t, fake.uploads)
+}
+
+func TestUploadLeavePartsOnError(t *testing.T) {
+	fake, svc := newFakeS3(t)
+	fake.failPart = 2
+
+	_, err := NewUploader(svc, func(u *Uploader) { u.LeavePartsOnError = true }).Upload(context.Background(), &UploadInput{
+		Bucket: "myBucket",
+		Key:    "large.bin",
+		Body:   onlyReader{bytes.NewReader(randomBytes(t, 3*MinUploadPartSize))},
+	})
+
+	var uploadErr *MultipartUploadError
+	require.ErrorAs(t, err, &uploadErr)
+	require.Zero(t, fake.count("AbortMultipartUpload"))
+	require.Contains(t, fake.uploads, uploadErr.UploadID)
+}
+
+// cancelReader cancels the context once the given number of bytes is read.
+type cancelReader struct {
+	r      io.Reader
+	after  int64
+	read   int64
+	cancel context.CancelFunc
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	if r.read >= r.after {
+		r.cancel()
+	}
+
+	return n, err
+}
+
+func TestUploadContextCanceled(t *testing.T) {
+	fake, svc := newFakeS3(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := NewUploader(svc).Upload(ctx, &UploadInput{
+		Bucket: "myBucket",
+		Key:    "large.bin",
+		Body: &cancelReader{
+			r:      bytes.NewReader(randomBytes(t, 4*MinUploadPartSize)),
+			after:  2*MinUploadPartSize + 1,
+			cancel: cancel,
+		},
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	var uploadErr *MultipartUploadError
+	require.ErrorAs(t, err, &uploadErr)
+	require.Equal(t, 1, fake.count("AbortMultipartUpload"))
+	require.Empty(t, fake.uploads)
+}
+
+func TestUploadReadError(t *testing.T) {
+	fake, svc := newFakeS3(t)
+	readErr := errors.New("disk on fire")
+
+	_, err := NewUploader(svc).Upload(context.Background(), &UploadInput{
+		Bucket: "myBucket",
+		Key:    "large.bin",
+		Body:   io.MultiReader(bytes.NewReader(randomBytes(t, 2*MinUploadPartSize+1)), iotestErrReader{readErr}),
+	})
+	require.ErrorIs(t, err, readErr)
+	require.Equal(t, 1, fake.count("AbortMultipartUpload"))
+}
+
+type iotestErrReader struct {
+	err error
+}
+
+func (r iotestErrReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestUploaderValidate(t *testing.T) {
+	_, svc := newFakeS3(t)
+
+	_, err := NewUploader(svc, func(u *Uploader) { u.PartSize = 1024 }).Upload(context.Background(), &UploadInput{})
+	require.ErrorContains(t, err, "part size")
+
+	_, err = NewUploader(svc, func(u *Uploader) { u.Concurrency = 0 }).Upload(context.Background(), &UploadInput{})
+	require.ErrorContains(t, err, "concurrency")
+}
+
+func TestPartPoolBounded(t *testing.T) {
+	pool := newPartPool(16, 2)
+
+	first, err := pool.get(context.Background())
+	require.NoError(t, err)
+	_, err = pool.get(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = pool.get(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	pool.put(first)
+	again, err := pool.get(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &first[0], &again[0])
+}
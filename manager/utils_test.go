@@ -0,0 +1,33 @@
+package manager
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/lvjp/raw-s3-sdk-go/service"
+	"github.com/stretchr/testify/require"
+)
+
+// newService returns a Service sending its requests to the handler.
+func newService(t *testing.T, handler http.Handler) *service.Service {
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	cfg := config.Config{
+		HTTPClient: ts.Client(),
+		Region:     "eu-west-1",
+		Credentials: config.Credentials{
+			AccessKey: "DUMMYAIOSFODNN7EXAMPLE",
+			SecretKey: "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY",
+		},
+		SignatureType: config.SignatureTypeV4,
+	}
+
+	var err error
+	cfg.Endpoint, err = config.NewEndpointFromURL(ts.URL)
+	require.NoError(t, err)
+
+	return service.New(cfg)
+}
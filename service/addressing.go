@@ -0,0 +1,102 @@
+package service
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/lvjp/raw-s3-sdk-go/signing/utils"
+)
+
+const standardHTTPPort = 80
+const standardHTTPSPort = 443
+
+const minBucketNameLength = 3
+const maxBucketNameLength = 63
+
+// newURL builds the request URL for the given bucket and key.
+//
+// Virtual-hosted style (bucket.host/key) is used when the endpoint asks for
+// it and the bucket name can be used as a DNS label. Otherwise, the request
+// falls back to path style (host/bucket/key).
+func (s *Service) newURL(bucket *string, key *string, queryString url.Values) *url.URL {
+	e := &s.config.Endpoint
+
+	url := &url.URL{
+		Host:     e.Host,
+		RawQuery: utils.CanonicalQueryString(queryString),
+	}
+
+	segments := make([]string, 0, 2)
+
+	if bucket != nil {
+		if useVirtualHost(e.WithVirtualHost, e.WithSSL, *bucket) {
+			url.Host = *bucket + "." + url.Host
+		} else {
+			segments = append(segments, *bucket)
+		}
+	}
+
+	if key != nil {
+		segments = append(segments, *key)
+	}
+
+	url.Path = "/" + strings.Join(segments, "/")
+	url.RawPath = utils.URIEncode(url.Path, false)
+
+	if e.WithSSL {
+		url.Scheme = "https"
+		if e.Port != standardHTTPSPort {
+			url.Host += ":" + strconv.Itoa(e.Port)
+		}
+	} else {
+		url.Scheme = "http"
+		if e.Port != standardHTTPPort {
+			url.Host += ":" + strconv.Itoa(e.Port)
+		}
+	}
+
+	return url
+}
+
+// useVirtualHost reports whether the bucket can be addressed as a sub-domain
+// of the endpoint. Buckets with dots are kept in the path under TLS because
+// they would not match the endpoint wildcard certificate.
+func useVirtualHost(withVirtualHost, withSSL bool, bucket string) bool {
+	if !withVirtualHost || !isDNSCompatibleBucketName(bucket) {
+		return false
+	}
+
+	return !withSSL || !strings.Contains(bucket, ".")
+}
+
+// isDNSCompatibleBucketName checks the bucket naming rules which allow a
+// bucket to be used as a host name label.
+func isDNSCompatibleBucketName(bucket string) bool {
+	if len(bucket) < minBucketNameLength || len(bucket) > maxBucketNameLength {
+		return false
+	}
+
+	if net.ParseIP(bucket) != nil {
+		return false
+	}
+
+	for _, label := range strings.Split(bucket, ".") {
+		if label == "" || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+
+		for i := 0; i < len(label); i++ {
+			switch c := label[i]; {
+			case 'a' <= c && c <= 'z':
+			case '0' <= c && c <= '9':
+			case c == '-':
+			default:
+				return false
+			}
+		}
+	}
+
+	return true
+}
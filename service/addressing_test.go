@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewURL(t *testing.T) {
+	plain := config.Endpoint{Host: "s3.example.com", Port: 80}
+	virtual := config.Endpoint{Host: "s3.example.com", Port: 80, WithVirtualHost: true}
+	virtualTLS := config.Endpoint{Host: "s3.example.com", Port: 443, WithSSL: true, WithVirtualHost: true}
+
+	str := func(s string) *string { return &s }
+
+	testCases := []struct {
+		name     string
+		endpoint config.Endpoint
+		bucket   *string
+		key      *string
+		query    url.Values
+		expected string
+	}{
+		{name: "Service", endpoint: plain, expected: "http://s3.example.com/"},
+		{name: "PathBucket", endpoint: plain, bucket: str("bucket"), expected: "http://s3.example.com/bucket"},
+		{name: "PathKey", endpoint: plain, bucket: str("bucket"), key: str("dir/file.txt"), expected: "http://s3.example.com/bucket/dir/file.txt"},
+		{name: "PathCustomPort", endpoint: config.Endpoint{Host: "localhost", Port: 9000}, bucket: str("bucket"), key: str("a"), expected: "http://localhost:9000/bucket/a"},
+		{name: "VirtualBucket", endpoint: virtual, bucket: str("bucket"), expected: "http://bucket.s3.example.com/"},
+		{name: "VirtualKey", endpoint: virtual, bucket: str("bucket"), key: str("dir/file.txt"), expected: "http://bucket.s3.example.com/dir/file.txt"},
+		{name: "VirtualDotsWithoutTLS", endpoint: virtual, bucket: str("my.bucket"), key: str("a"), expected: "http://my.bucket.s3.example.com/a"},
+		{name: "VirtualDotsWithTLS", endpoint: virtualTLS, bucket: str("my.bucket"), key: str("a"), expected: "https://s3.example.com/my.bucket/a"},
+		{name: "VirtualUppercase", endpoint: virtual, bucket: str("MyBucket"), key: str("a"), expected: "http://s3.example.com/MyBucket/a"},
+		{name: "VirtualUnderscore", endpoint: virtual, bucket: str("my_bucket"), key: str("a"), expected: "http://s3.example.com/my_bucket/a"},
+		{name: "VirtualIPAddress", endpoint: virtual, bucket: str("192.168.1.1"), key: str("a"), expected: "http://s3.example.com/192.168.1.1/a"},
+		{name: "VirtualTooShort", endpoint: virtual, bucket: str("ab"), key: str("a"), expected: "http://s3.example.com/ab/a"},
+		{name: "VirtualLeadingHyphen", endpoint: virtual, bucket: str("-bucket"), key: str("a"), expected: "http://s3.example.com/-bucket/a"},
+		{name: "VirtualDashDot", endpoint: virtual, bucket: str("my-.bucket"), key: str("a"), expected: "http://s3.example.com/my-.bucket/a"},
+		{name: "KeySpaces", endpoint: plain, bucket: str("bucket"), key: str("my file+name.txt"), expected: "http://s3.example.com/bucket/my%20file%2Bname.txt"},
+		{name: "KeyUnicode", endpoint: plain, bucket: str("bucket"), key: str("café/ü.txt"), expected: "http://s3.example.com/bucket/caf%C3%A9/%C3%BC.txt"},
+		{name: "KeyDoubleSlash", endpoint: plain, bucket: str("bucket"), key: str("a//b"), expected: "http://s3.example.com/bucket/a//b"},
+		{name: "KeyDotSegments", endpoint: plain, bucket: str("bucket"), key: str("a/../b/./c"), expected: "http://s3.example.com/bucket/a/../b/./c"},
+		{name: "KeyTrailingSlash", endpoint: virtual, bucket: str("bucket"), key: str("folder/"), expected: "http://bucket.s3.example.com/folder/"},
+		{name: "KeyLeadingSlash", endpoint: plain, bucket: str("bucket"), key: str("/abs"), expected: "http://s3.example.com/bucket//abs"},
+		{name: "QueryString", endpoint: plain, bucket: str("bucket"), query: url.Values{"prefix": {"a b/c"}, "delimiter": {"/"}}, expected: "http://s3.example.com/bucket?delimiter=%2F&prefix=a%20b%2Fc"},
+		{name: "QueryStringEmptyValue", endpoint: plain, bucket: str("bucket"), query: url.Values{"location": {""}}, expected: "http://s3.example.com/bucket?location="},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := New(config.Config{Endpoint: tc.endpoint})
+			require.Equal(t, tc.expected, s.newURL(tc.bucket, tc.key, tc.query).String())
+		})
+	}
+}
+
+func TestAddressingMatchesAWS(t *testing.T) {
+	var requestURI string
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.URL.EscapedPath()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+
+	for _, key := range []string{"simple.txt", "a b/c+d", "日本語/ключ", "a//b/../c/./d", "trailing/", "~!*'()$&,;=:@"} {
+		t.Run(key, func(t *testing.T) {
+			_, err := ourClient.HeadObject(context.Background(), &HeadObjectInput{Bucket: bucket, Key: key})
+			require.NoError(t, err)
+			ours := requestURI
+
+			_, err = awsClient.HeadObject(context.Background(), &s3.HeadObjectInput{Bucket: &bucket, Key: &key})
+			require.NoError(t, err)
+
+			require.Equal(t, requestURI, ours)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"net/http"
+)
+
+type AbortMultipartUploadInput struct {
+	Bucket   string
+	Key      string
+	UploadID string
+}
+
+type AbortMultipartUploadOutput struct {
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) AbortMultipartUpload(ctx context.Context, input *AbortMultipartUploadInput, optFns ...Option) (*AbortMultipartUploadOutput, error) {
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodDelete,
+			bucket: &input.Bucket,
+			key:    &input.Key,
+			query:  uploadQuery(input.UploadID),
+		},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AbortMultipartUploadOutput{
+		HTTPRequest:  req,
+		HTTPResponse: res,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbortMultipartUpload(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodDelete, r.Method)
+		require.Equal(t, "/myBucket/large.bin", r.URL.Path)
+
+		if r.URL.Query().Get("uploadId") != "upload" {
+			NewErrorResponseHandler(t, http.StatusNotFound, &types.Error{Code: "NoSuchUpload"})(w, r)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "large.bin"
+
+	t.Run("our", func(t *testing.T) {
+		_, err := ourClient.AbortMultipartUpload(context.Background(), &AbortMultipartUploadInput{
+			Bucket:   bucket,
+			Key:      key,
+			UploadID: "upload",
+		})
+		require.NoError(t, err)
+
+		_, err = ourClient.AbortMultipartUpload(context.Background(), &AbortMultipartUploadInput{
+			Bucket:   bucket,
+			Key:      key,
+			UploadID: "unknown",
+		})
+		require.ErrorIs(t, err, ErrNoSuchUpload)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		_, err := awsClient.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
+			Bucket:   &bucket,
+			Key:      &key,
+			UploadId: aws.String("upload"),
+		})
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+type CompleteMultipartUploadInput struct {
+	Bucket   string
+	Key      string
+	UploadID string
+
+	// Parts must be sorted by part number.
+	Parts []types.CompletedPart
+}
+
+type CompleteMultipartUploadOutput struct {
+	Payload types.CompleteMultipartUploadResult
+	// VersionID is the version created, when the bucket is versioned.
+	VersionID *string
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+// CompleteMultipartUpload assembles the uploaded parts. S3 may answer 200 and
+// still fail, with an <Error> document in the body: a *ResponseError is then
+// returned, as for any other error status.
+func (s *Service) CompleteMultipartUpload(ctx context.Context, input *CompleteMultipartUploadInput, optFns ...Option) (*CompleteMultipartUploadOutput, error) {
+	output := CompleteMultipartUploadOutput{}
+
+	body, err := xml.Marshal(&types.CompleteMultipartUpload{Parts: input.Parts})
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the completed parts: %w", err)
+	}
+
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodPost,
+			bucket: &input.Bucket,
+			key:    &input.Key,
+			query:  uploadQuery(input.UploadID),
+			header: http.Header{"Content-Type": []string{"application/xml"}},
+			body:   bytes.NewReader(body),
+
+			contentLength: int64(len(body)),
+		},
+		&output.Payload,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	output.VersionID = getStringHeader(res.Header, "X-Amz-Version-Id")
+	output.HTTPRequest = req
+	output.HTTPResponse = res
+
+	return &output, nil
+}
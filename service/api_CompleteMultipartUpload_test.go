@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteMultipartUpload(t *testing.T) {
+	expected := types.CompleteMultipartUploadResult{
+		Location: aws.String("http://myBucket.s3.amazonaws.com/large.bin"),
+		Bucket:   aws.String("myBucket"),
+		Key:      aws.String("large.bin"),
+		ETag:     aws.String(`"3858f62230ac3c915f300c664312c11f-2"`),
+	}
+
+	parts := []types.CompletedPart{
+		{ETag: aws.String(`"a54357aff0632cce46d942af68356b38"`), PartNumber: 1},
+		{ETag: aws.String(`"0c78aef83f66abc1fa1e8477f296d394"`), PartNumber: 2},
+	}
+
+	xmlHandler := NewSimpleXMLResponseHandler(t, &expected)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/myBucket/large.bin", r.URL.Path)
+		require.Equal(t, "upload", r.URL.Query().Get("uploadId"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var payload types.CompleteMultipartUpload
+		require.NoError(t, xml.Unmarshal(body, &payload))
+		require.Equal(t, parts, payload.Parts)
+
+		xmlHandler(w, r)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "large.bin"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.CompleteMultipartUpload(context.Background(), &CompleteMultipartUploadInput{
+			Bucket:   bucket,
+			Key:      key,
+			UploadID: "upload",
+			Parts:    parts,
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, output.Payload)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		awsParts := make([]s3types.CompletedPart, 0, len(parts))
+		for _, part := range parts {
+			awsParts = append(awsParts, s3types.CompletedPart{ETag: part.ETag, PartNumber: part.PartNumber})
+		}
+
+		s3out, err := awsClient.CompleteMultipartUpload(context.Background(), &s3.CompleteMultipartUploadInput{
+			Bucket:          &bucket,
+			Key:             &key,
+			UploadId:        aws.String("upload"),
+			MultipartUpload: &s3types.CompletedMultipartUpload{Parts: awsParts},
+		})
+		require.NoError(t, err)
+
+		s3out.ResultMetadata = middleware.Metadata{}
+		require.Equal(t, expected.ToAWS(t), s3out)
+	})
+}
+
+func TestCompleteMultipartUploadEmbeddedError(t *testing.T) {
+	expected := types.Error{
+		Code:      "InternalError",
+		Message:   "We encountered an internal error. Please try again.",
+		RequestID: "656c76696e6727732072657175657374",
+		HostID:    "Uuag1LuByRx9e6j5Onimru9pO4ZVKnJ2Qz7/C1NPcfTWAtRPfTaOFg==",
+	}
+
+	// S3 sends the 200 status as soon as the completion starts, then keeps
+	// the connection alive with whitespace until it fails.
+	raw, err := xml.Marshal(&expected)
+	require.NoError(t, err)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write(append([]byte(xml.Header+"\n  \n"), raw...))
+		require.NoError(t, err)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "large.bin"
+
+	t.Run("our", func(t *testing.T) {
+		_, err := ourClient.CompleteMultipartUpload(context.Background(), &CompleteMultipartUploadInput{
+			Bucket:   bucket,
+			Key:      key,
+			UploadID: "upload",
+		})
+
+		var respErr *ResponseError
+		require.ErrorAs(t, err, &respErr)
+		require.Equal(t, http.StatusOK, respErr.StatusCode)
+		require.Equal(t, expected, respErr.Payload)
+		require.Equal(t, expected.RequestID, respErr.RequestID)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		_, err := awsClient.CompleteMultipartUpload(
+			context.Background(),
+			&s3.CompleteMultipartUploadInput{
+				Bucket:   &bucket,
+				Key:      &key,
+				UploadId: aws.String("upload"),
+			},
+			func(o *s3.Options) { o.RetryMaxAttempts = 1 },
+		)
+
+		var apiErr smithy.APIError
+		require.ErrorAs(t, err, &apiErr)
+
+		awsExpected := expected.ToAWS(t)
+		require.Equal(t, awsExpected.ErrorCode(), apiErr.ErrorCode())
+		require.Equal(t, awsExpected.ErrorMessage(), apiErr.ErrorMessage())
+	})
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+const (
+	MetadataDirectiveCopy    = "COPY"
+	MetadataDirectiveReplace = "REPLACE"
+)
+
+type CopyObjectInput struct {
+	Bucket string
+	Key    string
+
+	// CopySource is the URL-encoded source object, as "bucket/key".
+	CopySource string
+	// CopySourceVersionID copies this version of the source instead of the
+	// current one.
+	CopySourceVersionID *string
+
+	CopySourceIfMatch           *string
+	CopySourceIfNoneMatch       *string
+	CopySourceIfModifiedSince   *time.Time
+	CopySourceIfUnmodifiedSince *time.Time
+
+	// MetadataDirective set to MetadataDirectiveReplace uses the headers and
+	// the metadata below instead of those of the source.
+	MetadataDirective  *string
+	CacheControl       *string
+	ContentDisposition *string
+	ContentEncoding    *string
+	ContentLanguage    *string
+	ContentType        *string
+	Expires            *time.Time
+	StorageClass       *string
+
+	Metadata map[string]string
+}
+
+type CopyObjectOutput struct {
+	Payload types.CopyObjectResult
+
+	// VersionID is the version created, when the destination bucket is
+	// versioned.
+	VersionID *string
+	// CopySourceVersionID is the version of the source that was copied.
+	CopySourceVersionID *string
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+// CopyObject copies an object server side. As for CompleteMultipartUpload, S3
+// may answer 200 and still fail: a *ResponseError is then returned.
+func (s *Service) CopyObject(ctx context.Context, input *CopyObjectInput, optFns ...Option) (*CopyObjectOutput, error) {
+	output := CopyObjectOutput{}
+
+	copySource := input.CopySource
+	if input.CopySourceVersionID != nil {
+		copySource += "?versionId=" + url.QueryEscape(*input.CopySourceVersionID)
+	}
+
+	header := http.Header{}
+	header.Set("X-Amz-Copy-Source", copySource)
+	setStringHeader(header, "X-Amz-Copy-Source-If-Match", input.CopySourceIfMatch)
+	setStringHeader(header, "X-Amz-Copy-Source-If-None-Match", input.CopySourceIfNoneMatch)
+	setTimeHeader(header, "X-Amz-Copy-Source-If-Modified-Since", input.CopySourceIfModifiedSince)
+	setTimeHeader(header, "X-Amz-Copy-Source-If-Unmodified-Since", input.CopySourceIfUnmodifiedSince)
+	setStringHeader(header, "X-Amz-Metadata-Directive", input.MetadataDirective)
+	setStringHeader(header, "Cache-Control", input.CacheControl)
+	setStringHeader(header, "Content-Disposition", input.ContentDisposition)
+	setStringHeader(header, "Content-Encoding", input.ContentEncoding)
+	setStringHeader(header, "Content-Language", input.ContentLanguage)
+	setStringHeader(header, "Content-Type", input.ContentType)
+	setTimeHeader(header, "Expires", input.Expires)
+	setStringHeader(header, "X-Amz-Storage-Class", input.StorageClass)
+	setMetadataHeaders(header, input.Metadata)
+
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodPut,
+			bucket: &input.Bucket,
+			key:    &input.Key,
+			header: header,
+		},
+		&output.Payload,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	output.VersionID = getStringHeader(res.Header, "X-Amz-Version-Id")
+	output.CopySourceVersionID = getStringHeader(res.Header, "X-Amz-Copy-Source-Version-Id")
+	output.HTTPRequest = req
+	output.HTTPResponse = res
+
+	return &output, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyObject(t *testing.T) {
+	expected := types.CopyObjectResult{
+		ETag:         aws.String(`"9b2cf535f27731c974343645a3985328"`),
+		LastModified: aws.String("2023-03-01T12:00:00Z"),
+	}
+
+	xmlHandler := NewSimpleXMLResponseHandler(t, &expected)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "/myBucket/copy.bin", r.URL.Path)
+		require.Equal(t, "source/object.bin?versionId=v1", r.Header.Get("X-Amz-Copy-Source"))
+		require.Equal(t, "REPLACE", r.Header.Get("X-Amz-Metadata-Directive"))
+		require.Equal(t, "text/plain", r.Header.Get("Content-Type"))
+		require.Equal(t, "blue", r.Header.Get("X-Amz-Meta-Color"))
+
+		w.Header().Set("X-Amz-Version-Id", "v2")
+		w.Header().Set("X-Amz-Copy-Source-Version-Id", "v1")
+		xmlHandler(w, r)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "copy.bin"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.CopyObject(context.Background(), &CopyObjectInput{
+			Bucket:              bucket,
+			Key:                 key,
+			CopySource:          "source/object.bin",
+			CopySourceVersionID: aws.String("v1"),
+			MetadataDirective:   aws.String(MetadataDirectiveReplace),
+			ContentType:         aws.String("text/plain"),
+			Metadata:            map[string]string{"color": "blue"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, output.Payload)
+		require.Equal(t, aws.String("v2"), output.VersionID)
+		require.Equal(t, aws.String("v1"), output.CopySourceVersionID)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.CopyObject(context.Background(), &s3.CopyObjectInput{
+			Bucket:            &bucket,
+			Key:               &key,
+			CopySource:        aws.String("source/object.bin?versionId=v1"),
+			MetadataDirective: s3types.MetadataDirectiveReplace,
+			ContentType:       aws.String("text/plain"),
+			Metadata:          map[string]string{"color": "blue"},
+		})
+		require.NoError(t, err)
+
+		awsExpected := expected.ToAWS(t)
+		awsExpected.VersionId = aws.String("v2")
+		awsExpected.CopySourceVersionId = aws.String("v1")
+
+		s3out.ResultMetadata = middleware.Metadata{}
+		require.Equal(t, awsExpected, s3out)
+	})
+}
+
+func TestCopyObjectEmbeddedError(t *testing.T) {
+	expected := types.Error{
+		Code:      "InternalError",
+		Message:   "We encountered an internal error. Please try again.",
+		RequestID: "656c76696e6727732072657175657374",
+		HostID:    "Uuag1LuByRx9e6j5Onimru9pO4ZVKnJ2Qz7/C1NPcfTWAtRPfTaOFg==",
+	}
+
+	raw, err := xml.Marshal(&expected)
+	require.NoError(t, err)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write(append([]byte(xml.Header), raw...))
+		require.NoError(t, err)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "copy.bin"
+
+	t.Run("our", func(t *testing.T) {
+		_, err := ourClient.CopyObject(context.Background(), &CopyObjectInput{
+			Bucket:     bucket,
+			Key:        key,
+			CopySource: "source/object.bin",
+		})
+
+		var respErr *ResponseError
+		require.ErrorAs(t, err, &respErr)
+		require.Equal(t, http.StatusOK, respErr.StatusCode)
+		require.Equal(t, expected, respErr.Payload)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		_, err := awsClient.CopyObject(
+			context.Background(),
+			&s3.CopyObjectInput{
+				Bucket:     &bucket,
+				Key:        &key,
+				CopySource: aws.String("source/object.bin"),
+			},
+			func(o *s3.Options) { o.RetryMaxAttempts = 1 },
+		)
+
+		var apiErr smithy.APIError
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, expected.Code, apiErr.ErrorCode())
+	})
+}
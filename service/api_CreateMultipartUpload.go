@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+type CreateMultipartUploadInput struct {
+	Bucket string
+	Key    string
+
+	CacheControl       *string
+	ContentDisposition *string
+	ContentEncoding    *string
+	ContentLanguage    *string
+	ContentType        *string
+	Expires            *time.Time
+	StorageClass       *string
+
+	Metadata map[string]string
+}
+
+type CreateMultipartUploadOutput struct {
+	Payload types.InitiateMultipartUploadResult
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) CreateMultipartUpload(ctx context.Context, input *CreateMultipartUploadInput, optFns ...Option) (*CreateMultipartUploadOutput, error) {
+	output := CreateMultipartUploadOutput{}
+
+	header := http.Header{}
+	setStringHeader(header, "Cache-Control", input.CacheControl)
+	setStringHeader(header, "Content-Disposition", input.ContentDisposition)
+	setStringHeader(header, "Content-Encoding", input.ContentEncoding)
+	setStringHeader(header, "Content-Language", input.ContentLanguage)
+	setStringHeader(header, "Content-Type", input.ContentType)
+	setTimeHeader(header, "Expires", input.Expires)
+	setStringHeader(header, "X-Amz-Storage-Class", input.StorageClass)
+	setMetadataHeaders(header, input.Metadata)
+
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodPost,
+			bucket: &input.Bucket,
+			key:    &input.Key,
+			query:  url.Values{"uploads": []string{""}},
+			header: header,
+		},
+		&output.Payload,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	output.HTTPRequest = req
+	output.HTTPResponse = res
+
+	return &output, nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateMultipartUpload(t *testing.T) {
+	expected := types.InitiateMultipartUploadResult{
+		Bucket:   aws.String("myBucket"),
+		Key:      aws.String("large.bin"),
+		UploadID: aws.String("VXBsb2FkIElEIGZvciA2aWWpbmcncyBteS1tb3ZpZS5tMnRzIHVwbG9hZA"),
+	}
+
+	xmlHandler := NewSimpleXMLResponseHandler(t, &expected)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/myBucket/large.bin", r.URL.Path)
+		require.True(t, r.URL.Query().Has("uploads"))
+		require.Equal(t, "application/octet-stream", r.Header.Get("Content-Type"))
+		require.Equal(t, "bar", r.Header.Get("X-Amz-Meta-Foo"))
+
+		xmlHandler(w, r)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "large.bin"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.CreateMultipartUpload(context.Background(), &CreateMultipartUploadInput{
+			Bucket:      bucket,
+			Key:         key,
+			ContentType: aws.String("application/octet-stream"),
+			Metadata:    map[string]string{"foo": "bar"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, output.Payload)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.CreateMultipartUpload(context.Background(), &s3.CreateMultipartUploadInput{
+			Bucket:      &bucket,
+			Key:         &key,
+			ContentType: aws.String("application/octet-stream"),
+			Metadata:    map[string]string{"foo": "bar"},
+		})
+		require.NoError(t, err)
+
+		s3out.ResultMetadata = middleware.Metadata{}
+		require.Equal(t, expected.ToAWS(t), s3out)
+	})
+}
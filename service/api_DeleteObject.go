@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+type DeleteObjectInput struct {
+	Bucket string
+	Key    string
+	// VersionID deletes this version for good. Without it, a versioned bucket
+	// keeps the object and adds a delete marker as its current version.
+	VersionID *string
+
+	// MFA is the serial number of the device, a space and the code it
+	// displays. It is required to delete a version when MFA Delete is enabled.
+	MFA *string
+}
+
+type DeleteObjectOutput struct {
+	// VersionID is the version deleted, or the delete marker created.
+	VersionID *string
+	// DeleteMarker reports that a delete marker was created or deleted.
+	DeleteMarker bool
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) DeleteObject(ctx context.Context, input *DeleteObjectInput, optFns ...Option) (*DeleteObjectOutput, error) {
+	query := url.Values{}
+	setStringQuery(query, "versionId", input.VersionID)
+
+	header := http.Header{}
+	setStringHeader(header, "X-Amz-Mfa", input.MFA)
+
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodDelete,
+			bucket: &input.Bucket,
+			key:    &input.Key,
+			query:  query,
+			header: header,
+		},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeleteObjectOutput{
+		VersionID:    getStringHeader(res.Header, "X-Amz-Version-Id"),
+		DeleteMarker: getBoolHeader(res.Header, "X-Amz-Delete-Marker"),
+		HTTPRequest:  req,
+		HTTPResponse: res,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteObject(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodDelete, r.Method)
+		require.Equal(t, "/myBucket/old.log", r.URL.Path)
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "old.log"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.DeleteObject(context.Background(), &DeleteObjectInput{
+			Bucket: bucket,
+			Key:    key,
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, output.HTTPResponse.StatusCode)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		_, err := awsClient.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+			Bucket: &bucket,
+			Key:    &key,
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestDeleteObjectVersion(t *testing.T) {
+	mfa := "arn:aws:iam::123456789012:mfa/user 123456"
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodDelete, r.Method)
+		require.Equal(t, "marker", r.URL.Query().Get("versionId"))
+		require.Equal(t, mfa, r.Header.Get("X-Amz-Mfa"))
+
+		w.Header().Set("X-Amz-Version-Id", "marker")
+		w.Header().Set("X-Amz-Delete-Marker", "true")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "old.log"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.DeleteObject(context.Background(), &DeleteObjectInput{
+			Bucket:    bucket,
+			Key:       key,
+			VersionID: aws.String("marker"),
+			MFA:       &mfa,
+		})
+		require.NoError(t, err)
+		require.Equal(t, aws.String("marker"), output.VersionID)
+		require.True(t, output.DeleteMarker)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+			Bucket:    &bucket,
+			Key:       &key,
+			VersionId: aws.String("marker"),
+			MFA:       &mfa,
+		})
+		require.NoError(t, err)
+		require.Equal(t, aws.String("marker"), s3out.VersionId)
+		require.True(t, s3out.DeleteMarker)
+	})
+}
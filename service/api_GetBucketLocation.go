@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"net/http"
+	"net/url"
 
 	"github.com/lvjp/raw-s3-sdk-go/types"
 )
@@ -14,10 +15,18 @@ type GetBucketLocationOutput struct {
 	HTTPResponse *http.Response
 }
 
-func (s *Service) GetBucketLocation(ctx context.Context, bucket string) (*GetBucketLocationOutput, error) {
+func (s *Service) GetBucketLocation(ctx context.Context, bucket string, optFns ...Option) (*GetBucketLocationOutput, error) {
 	output := GetBucketLocationOutput{}
 
-	req, res, err := s.doCall(ctx, &output.Payload)
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodGet,
+			bucket: &bucket,
+			query:  url.Values{"location": []string{""}},
+		},
+		&output.Payload,
+	)
 	if err != nil {
 		return nil, err
 	}
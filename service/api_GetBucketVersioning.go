@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+type GetBucketVersioningOutput struct {
+	// Payload has no Status when versioning was never enabled on the bucket.
+	Payload types.VersioningConfiguration
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) GetBucketVersioning(ctx context.Context, bucket string, optFns ...Option) (*GetBucketVersioningOutput, error) {
+	output := GetBucketVersioningOutput{}
+
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodGet,
+			bucket: &bucket,
+			query:  url.Values{"versioning": []string{""}},
+		},
+		&output.Payload,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	output.HTTPRequest = req
+	output.HTTPResponse = res
+
+	return &output, nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBucketVersioning(t *testing.T) {
+	expected := types.VersioningConfiguration{
+		Status:    aws.String(BucketVersioningStatusEnabled),
+		MFADelete: aws.String(MFADeleteDisabled),
+	}
+
+	xmlHandler := NewSimpleXMLResponseHandler(t, &expected)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/myBucket", r.URL.Path)
+		require.Contains(t, r.URL.Query(), "versioning")
+
+		xmlHandler(w, r)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.GetBucketVersioning(context.Background(), bucket)
+		require.NoError(t, err)
+		require.Equal(t, expected, output.Payload)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.GetBucketVersioning(context.Background(), &s3.GetBucketVersioningInput{Bucket: &bucket})
+		require.NoError(t, err)
+
+		s3out.ResultMetadata = middleware.Metadata{}
+		require.Equal(t, expected.ToAWS(t), s3out)
+	})
+}
+
+func TestGetBucketVersioningNeverEnabled(t *testing.T) {
+	expected := types.VersioningConfiguration{}
+
+	ts, ourClient, awsClient := NewServer(t, NewSimpleXMLResponseHandler(t, &expected))
+	defer ts.Close()
+
+	bucket := "myBucket"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.GetBucketVersioning(context.Background(), bucket)
+		require.NoError(t, err)
+		require.Nil(t, output.Payload.Status)
+		require.Nil(t, output.Payload.MFADelete)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.GetBucketVersioning(context.Background(), &s3.GetBucketVersioningInput{Bucket: &bucket})
+		require.NoError(t, err)
+
+		s3out.ResultMetadata = middleware.Metadata{}
+		require.Equal(t, expected.ToAWS(t), s3out)
+	})
+}
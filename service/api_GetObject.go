@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type GetObjectInput struct {
+	Bucket string
+	Key    string
+	// VersionID selects a version of the object instead of the current one.
+	VersionID *string
+
+	Range *string
+	// PartNumber selects a part of an object uploaded with multipart. It
+	// cannot be combined with Range.
+	PartNumber *int32
+
+	IfMatch           *string
+	IfNoneMatch       *string
+	IfModifiedSince   *time.Time
+	IfUnmodifiedSince *time.Time
+}
+
+type GetObjectOutput struct {
+	// Body streams the object content. The caller must close it.
+	Body io.ReadCloser
+
+	ObjectHeaders
+
+	ContentRange *string
+	// PartsCount is the number of parts of a multipart object, returned when
+	// PartNumber is set.
+	PartsCount *int32
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) GetObject(ctx context.Context, input *GetObjectInput, optFns ...Option) (*GetObjectOutput, error) {
+	header := http.Header{}
+	setStringHeader(header, "Range", input.Range)
+	setConditionalHeaders(header, input.IfMatch, input.IfNoneMatch, input.IfModifiedSince, input.IfUnmodifiedSince)
+
+	query := url.Values{}
+	setInt32Query(query, "partNumber", input.PartNumber)
+	setStringQuery(query, "versionId", input.VersionID)
+
+	req, res, err := s.withOptions(optFns).call(
+		ctx,
+		&operation{
+			method: http.MethodGet,
+			bucket: &input.Bucket,
+			key:    &input.Key,
+			query:  query,
+			header: header,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetObjectOutput{
+		Body:          res.Body,
+		ObjectHeaders: newObjectHeaders(res),
+		ContentRange:  getStringHeader(res.Header, "Content-Range"),
+		PartsCount:    getInt32Header(res.Header, "X-Amz-Mp-Parts-Count"),
+		HTTPRequest:   req,
+		HTTPResponse:  res,
+	}, nil
+}
+
+// ObjectHeaders holds the object attributes S3 returns as response headers
+// on GetObject and HeadObject.
+type ObjectHeaders struct {
+	ContentLength int64
+
+	AcceptRanges       *string
+	CacheControl       *string
+	ContentDisposition *string
+	ContentEncoding    *string
+	ContentLanguage    *string
+	ContentType        *string
+	ETag               *string
+	Expires            *time.Time
+	LastModified       *time.Time
+	StorageClass       *string
+
+	// VersionID is the version of the object, when the bucket is versioned.
+	// A delete marker fails with a *ResponseError, whose DeleteMarker is set.
+	VersionID *string
+
+	Metadata map[string]string
+}
+
+func newObjectHeaders(res *http.Response) ObjectHeaders {
+	h := res.Header
+
+	return ObjectHeaders{
+		ContentLength: res.ContentLength,
+
+		AcceptRanges:       getStringHeader(h, "Accept-Ranges"),
+		CacheControl:       getStringHeader(h, "Cache-Control"),
+		ContentDisposition: getStringHeader(h, "Content-Disposition"),
+		ContentEncoding:    getStringHeader(h, "Content-Encoding"),
+		ContentLanguage:    getStringHeader(h, "Content-Language"),
+		ContentType:        getStringHeader(h, "Content-Type"),
+		ETag:               getStringHeader(h, "ETag"),
+		Expires:            getTimeHeader(h, "Expires"),
+		LastModified:       getTimeHeader(h, "Last-Modified"),
+		StorageClass:       getStringHeader(h, "X-Amz-Storage-Class"),
+
+		VersionID: getStringHeader(h, "X-Amz-Version-Id"),
+
+		Metadata: getMetadataHeaders(h),
+	}
+}
+
+func setConditionalHeaders(header http.Header, ifMatch, ifNoneMatch *string, ifModifiedSince, ifUnmodifiedSince *time.Time) {
+	setStringHeader(header, "If-Match", ifMatch)
+	setStringHeader(header, "If-None-Match", ifNoneMatch)
+	setTimeHeader(header, "If-Modified-Since", ifModifiedSince)
+	setTimeHeader(header, "If-Unmodified-Since", ifUnmodifiedSince)
+}
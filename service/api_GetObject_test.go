@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetObject(t *testing.T) {
+	const content = "0123456789"
+	lastModified := time.Date(2023, time.February, 3, 4, 5, 6, 0, time.UTC)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/myBucket/path/to/object.txt", r.URL.Path)
+		require.Equal(t, "bytes=2-5", r.Header.Get("Range"))
+		require.Equal(t, `"abc"`, r.Header.Get("If-Match"))
+
+		headers := w.Header()
+		headers.Set("Content-Type", "text/plain")
+		headers.Set("Content-Range", "bytes 2-5/10")
+		headers.Set("ETag", `"abc"`)
+		headers.Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		headers.Set("X-Amz-Meta-Owner", "alice")
+
+		w.WriteHeader(http.StatusPartialContent)
+		_, err := io.WriteString(w, content[2:6])
+		require.NoError(t, err)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "path/to/object.txt"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.GetObject(context.Background(), &GetObjectInput{
+			Bucket:  bucket,
+			Key:     key,
+			Range:   aws.String("bytes=2-5"),
+			IfMatch: aws.String(`"abc"`),
+		})
+		require.NoError(t, err)
+		defer output.Body.Close()
+
+		body, err := io.ReadAll(output.Body)
+		require.NoError(t, err)
+		require.Equal(t, "2345", string(body))
+
+		require.Equal(t, int64(4), output.ContentLength)
+		require.Equal(t, aws.String("text/plain"), output.ContentType)
+		require.Equal(t, aws.String("bytes 2-5/10"), output.ContentRange)
+		require.Equal(t, aws.String(`"abc"`), output.ETag)
+		require.Equal(t, &lastModified, output.LastModified)
+		require.Equal(t, map[string]string{"owner": "alice"}, output.Metadata)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.GetObject(context.Background(), &s3.GetObjectInput{
+			Bucket:  &bucket,
+			Key:     &key,
+			Range:   aws.String("bytes=2-5"),
+			IfMatch: aws.String(`"abc"`),
+		})
+		require.NoError(t, err)
+		defer s3out.Body.Close()
+
+		body, err := io.ReadAll(s3out.Body)
+		require.NoError(t, err)
+		require.Equal(t, "2345", string(body))
+
+		require.Equal(t, int64(4), s3out.ContentLength)
+		require.Equal(t, aws.String("text/plain"), s3out.ContentType)
+		require.Equal(t, aws.String("bytes 2-5/10"), s3out.ContentRange)
+		require.Equal(t, aws.String(`"abc"`), s3out.ETag)
+		require.Equal(t, &lastModified, s3out.LastModified)
+		require.Equal(t, map[string]string{"owner": "alice"}, s3out.Metadata)
+	})
+}
+
+func TestGetObjectPartNumber(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "2", r.URL.Query().Get("partNumber"))
+		require.Empty(t, r.Header.Get("Range"))
+
+		headers := w.Header()
+		headers.Set("Content-Range", "bytes 5-9/10")
+		headers.Set("X-Amz-Mp-Parts-Count", "2")
+
+		w.WriteHeader(http.StatusPartialContent)
+		_, err := io.WriteString(w, "56789")
+		require.NoError(t, err)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "multipart.bin"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.GetObject(context.Background(), &GetObjectInput{
+			Bucket:     bucket,
+			Key:        key,
+			PartNumber: aws.Int32(2),
+		})
+		require.NoError(t, err)
+		defer output.Body.Close()
+
+		require.Equal(t, aws.Int32(2), output.PartsCount)
+		require.Equal(t, aws.String("bytes 5-9/10"), output.ContentRange)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.GetObject(context.Background(), &s3.GetObjectInput{
+			Bucket:     &bucket,
+			Key:        &key,
+			PartNumber: 2,
+		})
+		require.NoError(t, err)
+		defer s3out.Body.Close()
+
+		require.Equal(t, int32(2), s3out.PartsCount)
+		require.Equal(t, aws.String("bytes 5-9/10"), s3out.ContentRange)
+	})
+}
+
+func TestGetObjectVersion(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "v1", r.URL.Query().Get("versionId"))
+
+		w.Header().Set("X-Amz-Version-Id", "v1")
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, "old")
+		require.NoError(t, err)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "object.txt"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.GetObject(context.Background(), &GetObjectInput{
+			Bucket:    bucket,
+			Key:       key,
+			VersionID: aws.String("v1"),
+		})
+		require.NoError(t, err)
+		defer output.Body.Close()
+
+		require.Equal(t, aws.String("v1"), output.VersionID)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.GetObject(context.Background(), &s3.GetObjectInput{
+			Bucket:    &bucket,
+			Key:       &key,
+			VersionId: aws.String("v1"),
+		})
+		require.NoError(t, err)
+		defer s3out.Body.Close()
+
+		require.Equal(t, aws.String("v1"), s3out.VersionId)
+		require.False(t, s3out.DeleteMarker)
+	})
+}
+
+func TestGetObjectDeleteMarker(t *testing.T) {
+	testCases := map[string]struct {
+		versionID *string
+		payload   types.Error
+		expected  error
+	}{
+		"Current": {
+			payload:  types.Error{Code: "NoSuchKey", Message: "The specified key does not exist."},
+			expected: ErrNoSuchKey,
+		},
+		"Requested": {
+			versionID: aws.String("marker"),
+			payload:   types.Error{Code: "MethodNotAllowed", Message: "The specified method is not allowed against this resource."},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			statusCode := http.StatusNotFound
+			if tc.versionID != nil {
+				statusCode = http.StatusMethodNotAllowed
+			}
+
+			errorHandler := NewErrorResponseHandler(t, statusCode, &tc.payload)
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, aws.ToString(tc.versionID), r.URL.Query().Get("versionId"))
+
+				w.Header().Set("X-Amz-Version-Id", "marker")
+				w.Header().Set("X-Amz-Delete-Marker", "true")
+				errorHandler(w, r)
+			})
+
+			ts, ourClient, awsClient := NewServer(t, handler)
+			defer ts.Close()
+
+			bucket := "myBucket"
+			key := "object.txt"
+
+			t.Run("our", func(t *testing.T) {
+				_, err := ourClient.GetObject(context.Background(), &GetObjectInput{
+					Bucket:    bucket,
+					Key:       key,
+					VersionID: tc.versionID,
+				})
+
+				var respErr *ResponseError
+				require.ErrorAs(t, err, &respErr)
+				require.Equal(t, statusCode, respErr.StatusCode)
+				require.Equal(t, tc.payload.Code, respErr.Code())
+				require.Equal(t, aws.String("marker"), respErr.VersionID)
+				require.True(t, respErr.DeleteMarker)
+				if tc.expected != nil {
+					require.ErrorIs(t, err, tc.expected)
+				}
+			})
+
+			t.Run("aws", func(t *testing.T) {
+				_, err := awsClient.GetObject(
+					context.Background(),
+					&s3.GetObjectInput{
+						Bucket:    &bucket,
+						Key:       &key,
+						VersionId: tc.versionID,
+					},
+					func(o *s3.Options) { o.RetryMaxAttempts = 1 },
+				)
+
+				var apiErr smithy.APIError
+				require.ErrorAs(t, err, &apiErr)
+				require.Equal(t, tc.payload.Code, apiErr.ErrorCode())
+			})
+		})
+	}
+}
@@ -14,10 +14,17 @@ type HeadBucketOutput struct {
 	HTTPResponse *http.Response
 }
 
-func (s *Service) HeadBucket(ctx context.Context, bucket string) (*HeadBucketOutput, error) {
+func (s *Service) HeadBucket(ctx context.Context, bucket string, optFns ...Option) (*HeadBucketOutput, error) {
 	output := HeadBucketOutput{}
 
-	req, res, err := s.doCall(ctx, nil)
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodHead,
+			bucket: &bucket,
+		},
+		nil,
+	)
 	if err != nil {
 		return nil, err
 	}
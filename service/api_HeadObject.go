@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type HeadObjectInput struct {
+	Bucket string
+	Key    string
+	// VersionID selects a version of the object instead of the current one.
+	VersionID *string
+
+	Range *string
+
+	IfMatch           *string
+	IfNoneMatch       *string
+	IfModifiedSince   *time.Time
+	IfUnmodifiedSince *time.Time
+}
+
+type HeadObjectOutput struct {
+	ObjectHeaders
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) HeadObject(ctx context.Context, input *HeadObjectInput, optFns ...Option) (*HeadObjectOutput, error) {
+	header := http.Header{}
+	setStringHeader(header, "Range", input.Range)
+	setConditionalHeaders(header, input.IfMatch, input.IfNoneMatch, input.IfModifiedSince, input.IfUnmodifiedSince)
+
+	query := url.Values{}
+	setStringQuery(query, "versionId", input.VersionID)
+
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodHead,
+			bucket: &input.Bucket,
+			key:    &input.Key,
+			query:  query,
+			header: header,
+		},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HeadObjectOutput{
+		ObjectHeaders: newObjectHeaders(res),
+		HTTPRequest:   req,
+		HTTPResponse:  res,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeadObject(t *testing.T) {
+	lastModified := time.Date(2023, time.February, 3, 4, 5, 6, 0, time.UTC)
+	ifModifiedSince := lastModified.Add(-time.Hour)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodHead, r.Method)
+		require.Equal(t, "/myBucket/object.bin", r.URL.Path)
+		require.Equal(t, ifModifiedSince.Format(http.TimeFormat), r.Header.Get("If-Modified-Since"))
+
+		headers := w.Header()
+		headers.Set("Content-Length", "1024")
+		headers.Set("Content-Type", "application/octet-stream")
+		headers.Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+		headers.Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		headers.Set("X-Amz-Storage-Class", "STANDARD_IA")
+		headers.Set("X-Amz-Meta-Color", "blue")
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "object.bin"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.HeadObject(context.Background(), &HeadObjectInput{
+			Bucket:          bucket,
+			Key:             key,
+			IfModifiedSince: &ifModifiedSince,
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, int64(1024), output.ContentLength)
+		require.Equal(t, aws.String("application/octet-stream"), output.ContentType)
+		require.Equal(t, aws.String(`"d41d8cd98f00b204e9800998ecf8427e"`), output.ETag)
+		require.Equal(t, &lastModified, output.LastModified)
+		require.Equal(t, aws.String("STANDARD_IA"), output.StorageClass)
+		require.Equal(t, map[string]string{"color": "blue"}, output.Metadata)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.HeadObject(context.Background(), &s3.HeadObjectInput{
+			Bucket:          &bucket,
+			Key:             &key,
+			IfModifiedSince: &ifModifiedSince,
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, int64(1024), s3out.ContentLength)
+		require.Equal(t, aws.String("application/octet-stream"), s3out.ContentType)
+		require.Equal(t, aws.String(`"d41d8cd98f00b204e9800998ecf8427e"`), s3out.ETag)
+		require.Equal(t, &lastModified, s3out.LastModified)
+		require.Equal(t, "STANDARD_IA", string(s3out.StorageClass))
+		require.Equal(t, map[string]string{"color": "blue"}, s3out.Metadata)
+	})
+}
+
+func TestHeadObjectDeleteMarker(t *testing.T) {
+	testCases := map[string]struct {
+		versionID  *string
+		statusCode int
+	}{
+		"Current":   {statusCode: http.StatusNotFound},
+		"Requested": {versionID: aws.String("marker"), statusCode: http.StatusMethodNotAllowed},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, aws.ToString(tc.versionID), r.URL.Query().Get("versionId"))
+
+				w.Header().Set("X-Amz-Version-Id", "marker")
+				w.Header().Set("X-Amz-Delete-Marker", "true")
+				w.WriteHeader(tc.statusCode)
+			})
+
+			ts, ourClient, awsClient := NewServer(t, handler)
+			defer ts.Close()
+
+			bucket := "myBucket"
+			key := "object.bin"
+
+			t.Run("our", func(t *testing.T) {
+				_, err := ourClient.HeadObject(context.Background(), &HeadObjectInput{
+					Bucket:    bucket,
+					Key:       key,
+					VersionID: tc.versionID,
+				})
+
+				var respErr *ResponseError
+				require.ErrorAs(t, err, &respErr)
+				require.Equal(t, tc.statusCode, respErr.StatusCode)
+				require.Equal(t, aws.String("marker"), respErr.VersionID)
+				require.True(t, respErr.DeleteMarker)
+			})
+
+			t.Run("aws", func(t *testing.T) {
+				_, err := awsClient.HeadObject(
+					context.Background(),
+					&s3.HeadObjectInput{
+						Bucket:    &bucket,
+						Key:       &key,
+						VersionId: tc.versionID,
+					},
+					func(o *s3.Options) { o.RetryMaxAttempts = 1 },
+				)
+				require.Error(t, err)
+			})
+		})
+	}
+}
@@ -14,11 +14,11 @@ type ListBucketsOutput struct {
 	HTTPResponse *http.Response
 }
 
-func (s *Service) ListBuckets(ctx context.Context) (*ListBucketsOutput, error) {
+func (s *Service) ListBuckets(ctx context.Context, optFns ...Option) (*ListBucketsOutput, error) {
 	output := ListBucketsOutput{}
 	var err error
 
-	req, res, err := s.doCall(ctx, &output.Payload)
+	req, res, err := s.withOptions(optFns).doCall(ctx, &operation{method: http.MethodGet}, &output.Payload)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+type ListMultipartUploadsInput struct {
+	Bucket string
+
+	Delimiter      *string
+	EncodingType   *string
+	KeyMarker      *string
+	MaxUploads     *int32
+	Prefix         *string
+	UploadIDMarker *string
+}
+
+type ListMultipartUploadsOutput struct {
+	Payload types.ListMultipartUploadsResult
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) ListMultipartUploads(ctx context.Context, input *ListMultipartUploadsInput, optFns ...Option) (*ListMultipartUploadsOutput, error) {
+	output := ListMultipartUploadsOutput{}
+
+	query := url.Values{"uploads": []string{""}}
+	setStringQuery(query, "delimiter", input.Delimiter)
+	setStringQuery(query, "encoding-type", input.EncodingType)
+	setStringQuery(query, "key-marker", input.KeyMarker)
+	setInt32Query(query, "max-uploads", input.MaxUploads)
+	setStringQuery(query, "prefix", input.Prefix)
+	setStringQuery(query, "upload-id-marker", input.UploadIDMarker)
+
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodGet,
+			bucket: &input.Bucket,
+			query:  query,
+		},
+		&output.Payload,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	output.HTTPRequest = req
+	output.HTTPResponse = res
+
+	return &output, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListMultipartUploads(t *testing.T) {
+	owner := &types.Owner{
+		DisplayName: aws.String("Account+Name"),
+		ID:          aws.String("DUMMYACKCEVSQ6C2EXAMPLE"),
+	}
+
+	expected := types.ListMultipartUploadsResult{
+		Bucket:             aws.String("myBucket"),
+		KeyMarker:          aws.String(""),
+		UploadIDMarker:     aws.String(""),
+		NextKeyMarker:      aws.String("photos/2006/March/sample.jpg"),
+		NextUploadIDMarker: aws.String("VXBsb2FkIElEIGZvciBlbHZpbmcncyBteS1tb3ZpZS5tMnRzIHVwbG9hZA"),
+		Prefix:             aws.String("photos/"),
+		Delimiter:          aws.String("/"),
+		MaxUploads:         3,
+		IsTruncated:        true,
+		Uploads: []types.Upload{
+			{
+				Key:          aws.String("photos/my-movie.m2ts"),
+				UploadID:     aws.String("XXBsb2FkIElEIGZvciBlbHZpbmcncyBteS1tb3ZpZS5tMnRzIHVwbG9hZA"),
+				Initiator:    &types.Initiator{DisplayName: aws.String("InitiatorDisplayName"), ID: aws.String("arn:aws:iam::111122223333:user/user1")},
+				Owner:        owner,
+				StorageClass: aws.String("STANDARD"),
+				Initiated:    aws.String("2010-11-10T20:48:33Z"),
+			},
+		},
+		CommonPrefixes: []types.CommonPrefix{
+			{Prefix: aws.String("photos/2006/")},
+		},
+	}
+
+	xmlHandler := NewSimpleXMLResponseHandler(t, &expected)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/myBucket", r.URL.Path)
+		require.True(t, r.URL.Query().Has("uploads"))
+		require.Equal(t, "photos/", r.URL.Query().Get("prefix"))
+		require.Equal(t, "/", r.URL.Query().Get("delimiter"))
+		require.Equal(t, "3", r.URL.Query().Get("max-uploads"))
+
+		xmlHandler(w, r)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.ListMultipartUploads(context.Background(), &ListMultipartUploadsInput{
+			Bucket:     bucket,
+			Delimiter:  aws.String("/"),
+			MaxUploads: aws.Int32(3),
+			Prefix:     aws.String("photos/"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, output.Payload)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.ListMultipartUploads(context.Background(), &s3.ListMultipartUploadsInput{
+			Bucket:     &bucket,
+			Delimiter:  aws.String("/"),
+			MaxUploads: 3,
+			Prefix:     aws.String("photos/"),
+		})
+		require.NoError(t, err)
+
+		s3out.ResultMetadata = middleware.Metadata{}
+		require.Equal(t, expected.ToAWS(t), s3out)
+	})
+}
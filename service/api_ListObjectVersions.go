@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+type ListObjectVersionsInput struct {
+	Bucket string
+
+	Delimiter *string
+	// EncodingType set to EncodingTypeURL lists keys with any character.
+	EncodingType *string
+	KeyMarker    *string
+	MaxKeys      *int32
+	Prefix       *string
+	// VersionIDMarker resumes the listing after this version of KeyMarker.
+	VersionIDMarker *string
+}
+
+type ListObjectVersionsOutput struct {
+	// Payload holds the decoded keys, even when EncodingType is
+	// EncodingTypeURL.
+	Payload types.ListVersionsResult
+
+	// Entries are the versions, delete markers and common prefixes of Payload
+	// in the order of the response, which Payload splits by kind. It is empty
+	// for ListObjectVersionsStream.
+	Entries []VersionEntry
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) ListObjectVersions(ctx context.Context, input *ListObjectVersionsInput, optFns ...Option) (*ListObjectVersionsOutput, error) {
+	var entries []VersionEntry
+	collect := func(entry VersionEntry) error {
+		entries = append(entries, entry)
+		return nil
+	}
+
+	output, err := s.listObjectVersions(ctx, input, collect, optFns)
+	if err != nil {
+		return nil, err
+	}
+
+	output.Entries = entries
+
+	p := &output.Payload
+	for _, entry := range entries {
+		switch {
+		case entry.Version != nil:
+			p.Versions = append(p.Versions, *entry.Version)
+		case entry.DeleteMarker != nil:
+			p.DeleteMarkers = append(p.DeleteMarkers, *entry.DeleteMarker)
+		default:
+			p.CommonPrefixes = append(p.CommonPrefixes, types.CommonPrefix{Prefix: entry.CommonPrefix})
+		}
+	}
+
+	return output, nil
+}
+
+// ListObjectVersionsStream is ListObjectVersions calling fn with each
+// version, delete marker and common prefix as soon as it is parsed, in the
+// order of the response. They are not kept in the payload. An error returned
+// by fn stops the listing and is returned.
+func (s *Service) ListObjectVersionsStream(ctx context.Context, input *ListObjectVersionsInput, fn func(VersionEntry) error, optFns ...Option) (*ListObjectVersionsOutput, error) {
+	return s.listObjectVersions(ctx, input, fn, optFns)
+}
+
+func (s *Service) listObjectVersions(ctx context.Context, input *ListObjectVersionsInput, fn func(VersionEntry) error, optFns []Option) (*ListObjectVersionsOutput, error) {
+	output := ListObjectVersionsOutput{}
+	handlers := versionEntryHandlers(&output.Payload.EncodingType, fn)
+
+	query := url.Values{"versions": []string{""}}
+	setStringQuery(query, "delimiter", input.Delimiter)
+	setStringQuery(query, "encoding-type", input.EncodingType)
+	setStringQuery(query, "key-marker", input.KeyMarker)
+	setInt32Query(query, "max-keys", input.MaxKeys)
+	setStringQuery(query, "prefix", input.Prefix)
+	setStringQuery(query, "version-id-marker", input.VersionIDMarker)
+
+	req, res, err := s.withOptions(optFns).doStreamingCall(
+		ctx,
+		&operation{
+			method: http.MethodGet,
+			bucket: &input.Bucket,
+			query:  query,
+		},
+		&output.Payload,
+		handlers,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if err := decodeVersionsURLEncoded(&output.Payload); err != nil {
+		return nil, err
+	}
+
+	output.HTTPRequest = req
+	output.HTTPResponse = res
+
+	return &output, nil
+}
+
+// VersionEntry is a version, a delete marker, or a common prefix when listing
+// with a delimiter.
+type VersionEntry struct {
+	Version      *types.ObjectVersion
+	DeleteMarker *types.DeleteMarkerEntry
+	CommonPrefix *string
+}
+
+// Key is the key of the version or the delete marker, or the common prefix.
+func (e VersionEntry) Key() string {
+	switch {
+	case e.Version != nil:
+		return deref(e.Version.Key)
+	case e.DeleteMarker != nil:
+		return deref(e.DeleteMarker.Key)
+	default:
+		return deref(e.CommonPrefix)
+	}
+}
+
+func decodeVersionsURLEncoded(p *types.ListVersionsResult) error {
+	if deref(p.EncodingType) != EncodingTypeURL {
+		return nil
+	}
+
+	values := []*string{p.Prefix, p.Delimiter, p.KeyMarker, p.NextKeyMarker}
+	for i := range p.Versions {
+		values = append(values, p.Versions[i].Key)
+	}
+
+	for i := range p.DeleteMarkers {
+		values = append(values, p.DeleteMarkers[i].Key)
+	}
+
+	for i := range p.CommonPrefixes {
+		values = append(values, p.CommonPrefixes[i].Prefix)
+	}
+
+	return decodeURLValues(values...)
+}
+
+// versionEntryHandlers stream the versions, delete markers and common
+// prefixes of a listing to fn, decoding their keys as listEntryHandlers do.
+func versionEntryHandlers(encodingType **string, fn func(VersionEntry) error) elementHandlers {
+	decodeKey := func(key *string) error {
+		if deref(*encodingType) != EncodingTypeURL {
+			return nil
+		}
+
+		return decodeURLValues(key)
+	}
+
+	return elementHandlers{
+		"Version": func(d *xml.Decoder, start *xml.StartElement) error {
+			var version types.ObjectVersion
+			if err := d.DecodeElement(&version, start); err != nil {
+				return err
+			}
+
+			if err := decodeKey(version.Key); err != nil {
+				return err
+			}
+
+			return fn(VersionEntry{Version: &version})
+		},
+		"DeleteMarker": func(d *xml.Decoder, start *xml.StartElement) error {
+			var marker types.DeleteMarkerEntry
+			if err := d.DecodeElement(&marker, start); err != nil {
+				return err
+			}
+
+			if err := decodeKey(marker.Key); err != nil {
+				return err
+			}
+
+			return fn(VersionEntry{DeleteMarker: &marker})
+		},
+		"CommonPrefixes": func(d *xml.Decoder, start *xml.StartElement) error {
+			var prefix types.CommonPrefix
+			if err := d.DecodeElement(&prefix, start); err != nil {
+				return err
+			}
+
+			if err := decodeKey(prefix.Prefix); err != nil {
+				return err
+			}
+
+			return fn(VersionEntry{CommonPrefix: prefix.Prefix})
+		},
+	}
+}
+
+// ListObjectVersionsAPIClient is the operation used by the
+// ListObjectVersionsPaginator.
+type ListObjectVersionsAPIClient interface {
+	ListObjectVersions(context.Context, *ListObjectVersionsInput, ...Option) (*ListObjectVersionsOutput, error)
+}
+
+var _ ListObjectVersionsAPIClient = (*Service)(nil)
+
+// ListObjectVersionsPaginator walks the pages of a version listing,
+// following the next key and version ID markers.
+type ListObjectVersionsPaginator struct {
+	client  ListObjectVersionsAPIClient
+	input   ListObjectVersionsInput
+	optFns  []Option
+	started bool
+}
+
+func NewListObjectVersionsPaginator(client ListObjectVersionsAPIClient, input *ListObjectVersionsInput, optFns ...Option) *ListObjectVersionsPaginator {
+	return &ListObjectVersionsPaginator{
+		client: client,
+		input:  *input,
+		optFns: optFns,
+	}
+}
+
+func (p *ListObjectVersionsPaginator) HasMorePages() bool {
+	return !p.started || p.input.KeyMarker != nil
+}
+
+func (p *ListObjectVersionsPaginator) NextPage(ctx context.Context) (*ListObjectVersionsOutput, error) {
+	output, err := p.client.ListObjectVersions(ctx, &p.input, p.optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	previousKey, previousVersion := deref(p.input.KeyMarker), deref(p.input.VersionIDMarker)
+	p.started = true
+	p.input.KeyMarker = nil
+	p.input.VersionIDMarker = nil
+
+	nextKey, nextVersion := deref(output.Payload.NextKeyMarker), deref(output.Payload.NextVersionIDMarker)
+	if output.Payload.IsTruncated && nextKey != "" && (nextKey != previousKey || nextVersion != previousVersion) {
+		p.input.KeyMarker = &nextKey
+		if nextVersion != "" {
+			p.input.VersionIDMarker = &nextVersion
+		}
+	}
+
+	return output, nil
+}
+
+// All iterates over the versions, delete markers and common prefixes of the
+// remaining pages, in the order of the responses. A failed page is yielded as
+// an error, which ends the iteration. It has the signature of an
+// iter.Seq2[VersionEntry, error].
+func (p *ListObjectVersionsPaginator) All(ctx context.Context) func(yield func(VersionEntry, error) bool) {
+	return func(yield func(VersionEntry, error) bool) {
+		for p.HasMorePages() {
+			page, err := p.NextPage(ctx)
+			if err != nil {
+				yield(VersionEntry{}, err)
+				return
+			}
+
+			for _, entry := range page.Entries {
+				if !yield(entry, nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListObjectVersions(t *testing.T) {
+	expected := types.ListVersionsResult{
+		Name:                aws.String("myBucket"),
+		Prefix:              aws.String("photos/"),
+		KeyMarker:           aws.String("photos/2005"),
+		VersionIDMarker:     aws.String("3/L4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY"),
+		NextKeyMarker:       aws.String("photos/index.html"),
+		NextVersionIDMarker: aws.String("UIORUnfndfhnw89493jJFJ"),
+		MaxKeys:             3,
+		Delimiter:           aws.String("/"),
+		IsTruncated:         true,
+		Versions: []types.ObjectVersion{
+			{
+				Key:          aws.String("photos/index.html"),
+				VersionID:    aws.String("UIORUnfndfhnw89493jJFJ"),
+				IsLatest:     false,
+				LastModified: aws.String("2009-10-10T17:50:30Z"),
+				ETag:         aws.String(`"9b2cf535f27731c974343645a3985328"`),
+				Size:         166434,
+				StorageClass: aws.String("STANDARD"),
+				Owner: &types.Owner{
+					ID:          aws.String("75aa57f09aa0c8caeab4f8c24e99d10f8e7faeebf76c078efc7c6caea54ba06a"),
+					DisplayName: aws.String("mtd@amazon.com"),
+				},
+			},
+		},
+		DeleteMarkers: []types.DeleteMarkerEntry{
+			{
+				Key:          aws.String("photos/index.html"),
+				VersionID:    aws.String("03jpff543dhffds434rfdsFDN943fdsFkdmqnh892"),
+				IsLatest:     true,
+				LastModified: aws.String("2009-10-15T17:50:30Z"),
+			},
+		},
+		CommonPrefixes: []types.CommonPrefix{
+			{Prefix: aws.String("photos/2006/")},
+		},
+	}
+
+	xmlHandler := NewSimpleXMLResponseHandler(t, &expected)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/myBucket", r.URL.Path)
+
+		query := r.URL.Query()
+		require.True(t, query.Has("versions"))
+		require.Equal(t, "photos/2005", query.Get("key-marker"))
+		require.Equal(t, "3/L4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY", query.Get("version-id-marker"))
+		require.Equal(t, "/", query.Get("delimiter"))
+		require.Equal(t, "3", query.Get("max-keys"))
+		require.Equal(t, "photos/", query.Get("prefix"))
+
+		xmlHandler(w, r)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.ListObjectVersions(context.Background(), &ListObjectVersionsInput{
+			Bucket:          bucket,
+			Delimiter:       aws.String("/"),
+			KeyMarker:       aws.String("photos/2005"),
+			MaxKeys:         aws.Int32(3),
+			Prefix:          aws.String("photos/"),
+			VersionIDMarker: aws.String("3/L4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, output.Payload)
+
+		var entries []string
+		for _, entry := range output.Entries {
+			switch {
+			case entry.Version != nil:
+				entries = append(entries, "version "+entry.Key())
+			case entry.DeleteMarker != nil:
+				entries = append(entries, "marker "+entry.Key())
+			default:
+				entries = append(entries, "prefix "+entry.Key())
+			}
+		}
+		require.Equal(t, []string{"version photos/index.html", "marker photos/index.html", "prefix photos/2006/"}, entries)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.ListObjectVersions(context.Background(), &s3.ListObjectVersionsInput{
+			Bucket:          &bucket,
+			Delimiter:       aws.String("/"),
+			KeyMarker:       aws.String("photos/2005"),
+			MaxKeys:         3,
+			Prefix:          aws.String("photos/"),
+			VersionIdMarker: aws.String("3/L4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY"),
+		})
+		require.NoError(t, err)
+
+		s3out.ResultMetadata = middleware.Metadata{}
+		require.Equal(t, expected.ToAWS(t), s3out)
+	})
+}
+
+func TestListObjectVersionsStream(t *testing.T) {
+	page := types.ListVersionsResult{
+		Name:          aws.String("myBucket"),
+		EncodingType:  aws.String(EncodingTypeURL),
+		Versions:      []types.ObjectVersion{{Key: aws.String("a%20b"), VersionID: aws.String("v1")}},
+		DeleteMarkers: []types.DeleteMarkerEntry{{Key: aws.String("c%2Bd"), VersionID: aws.String("v2")}},
+	}
+
+	ts, ourClient, _ := NewServer(t, NewSimpleXMLResponseHandler(t, &page))
+	defer ts.Close()
+
+	var keys []string
+	output, err := ourClient.ListObjectVersionsStream(
+		context.Background(),
+		&ListObjectVersionsInput{Bucket: "myBucket", EncodingType: aws.String(EncodingTypeURL)},
+		func(entry VersionEntry) error {
+			keys = append(keys, entry.Key())
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a b", "c+d"}, keys)
+	require.Empty(t, output.Payload.Versions)
+	require.Empty(t, output.Payload.DeleteMarkers)
+	require.Equal(t, aws.String("myBucket"), output.Payload.Name)
+}
+
+// TestListObjectVersionsEncodingTypeOmitted requests URL-encoded keys from a
+// server that ignores it: neither the streamed nor the buffered keys are
+// decoded.
+func TestListObjectVersionsEncodingTypeOmitted(t *testing.T) {
+	page := types.ListVersionsResult{
+		Name:     aws.String("myBucket"),
+		Versions: []types.ObjectVersion{{Key: aws.String("a%20b+c"), VersionID: aws.String("v1")}},
+	}
+
+	ts, ourClient, _ := NewServer(t, NewSimpleXMLResponseHandler(t, &page))
+	defer ts.Close()
+
+	input := &ListObjectVersionsInput{Bucket: "myBucket", EncodingType: aws.String(EncodingTypeURL)}
+
+	var keys []string
+	_, err := ourClient.ListObjectVersionsStream(context.Background(), input, func(entry VersionEntry) error {
+		keys = append(keys, entry.Key())
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a%20b+c"}, keys)
+
+	output, err := ourClient.ListObjectVersions(context.Background(), input)
+	require.NoError(t, err)
+	require.Equal(t, "a%20b+c", output.Entries[0].Key())
+	require.Equal(t, aws.String("a%20b+c"), output.Payload.Versions[0].Key)
+}
+
+// TestListObjectVersionsDocumentOrder interleaves a delete marker between the
+// versions of a key, with the same LastModified: the entries must keep the
+// order of the response.
+func TestListObjectVersionsDocumentOrder(t *testing.T) {
+	const body = `<?xml version="1.0" encoding="UTF-8"?>
+<ListVersionsResult>
+	<Name>myBucket</Name>
+	<Version><Key>a</Key><VersionId>a3</VersionId><LastModified>2023-03-01T00:00:00.000Z</LastModified></Version>
+	<DeleteMarker><Key>a</Key><VersionId>a2</VersionId><LastModified>2023-03-01T00:00:00.000Z</LastModified></DeleteMarker>
+	<Version><Key>a</Key><VersionId>a1</VersionId><LastModified>2023-03-01T00:00:00.000Z</LastModified></Version>
+	<CommonPrefixes><Prefix>b/</Prefix></CommonPrefixes>
+</ListVersionsResult>`
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		_, err := io.WriteString(w, body)
+		require.NoError(t, err)
+	})
+
+	ts, ourClient, _ := NewServer(t, handler)
+	defer ts.Close()
+
+	input := &ListObjectVersionsInput{Bucket: "myBucket"}
+	expected := []string{"a3", "a2", "a1", "b/"}
+
+	id := func(entry VersionEntry) string {
+		switch {
+		case entry.Version != nil:
+			return *entry.Version.VersionID
+		case entry.DeleteMarker != nil:
+			return *entry.DeleteMarker.VersionID
+		default:
+			return *entry.CommonPrefix
+		}
+	}
+
+	var streamed []string
+	_, err := ourClient.ListObjectVersionsStream(context.Background(), input, func(entry VersionEntry) error {
+		streamed = append(streamed, id(entry))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, expected, streamed)
+
+	var iterated []string
+	NewListObjectVersionsPaginator(ourClient, input).All(context.Background())(func(entry VersionEntry, err error) bool {
+		require.NoError(t, err)
+		iterated = append(iterated, id(entry))
+		return true
+	})
+	require.Equal(t, expected, iterated)
+
+	output, err := ourClient.ListObjectVersions(context.Background(), input)
+	require.NoError(t, err)
+	require.Len(t, output.Payload.Versions, 2)
+	require.Len(t, output.Payload.DeleteMarkers, 1)
+	require.Len(t, output.Payload.CommonPrefixes, 1)
+}
+
+// listObjectVersionsPages serves the pages in order, one per call. The
+// entries of a page are its versions followed by its delete markers.
+type listObjectVersionsPages struct {
+	pages []types.ListVersionsResult
+	calls []*ListObjectVersionsInput
+}
+
+func (l *listObjectVersionsPages) ListObjectVersions(_ context.Context, input *ListObjectVersionsInput, _ ...Option) (*ListObjectVersionsOutput, error) {
+	copied := *input
+	l.calls = append(l.calls, &copied)
+
+	output := &ListObjectVersionsOutput{Payload: l.pages[len(l.calls)-1]}
+	for i := range output.Payload.Versions {
+		output.Entries = append(output.Entries, VersionEntry{Version: &output.Payload.Versions[i]})
+	}
+	for i := range output.Payload.DeleteMarkers {
+		output.Entries = append(output.Entries, VersionEntry{DeleteMarker: &output.Payload.DeleteMarkers[i]})
+	}
+
+	return output, nil
+}
+
+func TestListObjectVersionsPaginator(t *testing.T) {
+	version := func(key, id, lastModified string) types.ObjectVersion {
+		return types.ObjectVersion{Key: &key, VersionID: &id, LastModified: &lastModified}
+	}
+
+	client := &listObjectVersionsPages{pages: []types.ListVersionsResult{
+		{
+			IsTruncated:         true,
+			NextKeyMarker:       aws.String("a"),
+			NextVersionIDMarker: aws.String("a2"),
+			Versions: []types.ObjectVersion{
+				version("a", "a3", "2023-03-03T00:00:00Z"),
+				version("a", "a2", "2023-03-02T00:00:00Z"),
+			},
+		},
+		{
+			IsTruncated:         true,
+			NextKeyMarker:       aws.String("b"),
+			NextVersionIDMarker: aws.String("b1"),
+			Versions:            []types.ObjectVersion{version("a", "a1", "2023-03-01T00:00:00Z")},
+			DeleteMarkers: []types.DeleteMarkerEntry{
+				{Key: aws.String("b"), VersionID: aws.String("b1"), LastModified: aws.String("2023-03-04T00:00:00Z")},
+			},
+		},
+		{Versions: []types.ObjectVersion{version("c", "c1", "2023-03-01T00:00:00Z")}},
+	}}
+
+	paginator := NewListObjectVersionsPaginator(client, &ListObjectVersionsInput{Bucket: "myBucket"})
+
+	var ids []string
+	paginator.All(context.Background())(func(entry VersionEntry, err error) bool {
+		require.NoError(t, err)
+		if entry.DeleteMarker != nil {
+			ids = append(ids, *entry.DeleteMarker.VersionID)
+		} else {
+			ids = append(ids, *entry.Version.VersionID)
+		}
+		return true
+	})
+	require.Equal(t, []string{"a3", "a2", "a1", "b1", "c1"}, ids)
+
+	var markers [][2]*string
+	for _, call := range client.calls {
+		markers = append(markers, [2]*string{call.KeyMarker, call.VersionIDMarker})
+	}
+	require.Equal(t, [][2]*string{
+		{nil, nil},
+		{aws.String("a"), aws.String("a2")},
+		{aws.String("b"), aws.String("b1")},
+	}, markers)
+	require.False(t, paginator.HasMorePages())
+}
+
+func TestListObjectVersionsPaginatorRepeatedMarker(t *testing.T) {
+	page := types.ListVersionsResult{
+		IsTruncated:         true,
+		NextKeyMarker:       aws.String("a"),
+		NextVersionIDMarker: aws.String("a1"),
+	}
+	client := &listObjectVersionsPages{pages: []types.ListVersionsResult{page, page, page}}
+
+	paginator := NewListObjectVersionsPaginator(client, &ListObjectVersionsInput{Bucket: "myBucket"})
+	for paginator.HasMorePages() {
+		_, err := paginator.NextPage(context.Background())
+		require.NoError(t, err)
+	}
+
+	require.Len(t, client.calls, 2)
+}
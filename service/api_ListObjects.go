@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+// ListObjectsInput is the input of the version 1 of the listing, kept for the
+// gateways that do not support ListObjectsV2.
+type ListObjectsInput struct {
+	Bucket string
+
+	Delimiter *string
+	// EncodingType set to EncodingTypeURL lists keys with any character.
+	EncodingType *string
+	Marker       *string
+	MaxKeys      *int32
+	Prefix       *string
+}
+
+type ListObjectsOutput struct {
+	// Payload holds the decoded keys, even when EncodingType is
+	// EncodingTypeURL.
+	Payload types.ListBucketResult
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) ListObjects(ctx context.Context, input *ListObjectsInput, optFns ...Option) (*ListObjectsOutput, error) {
+	return s.listObjects(ctx, input, nil, optFns)
+}
+
+// ListObjectsStream is ListObjects calling fn with each object and common
+// prefix as soon as it is parsed, in the order of the response. They are not
+// kept in the payload, so that the memory used does not grow with the page
+// size. An error returned by fn stops the listing and is returned.
+func (s *Service) ListObjectsStream(ctx context.Context, input *ListObjectsInput, fn func(ListEntry) error, optFns ...Option) (*ListObjectsOutput, error) {
+	return s.listObjects(ctx, input, fn, optFns)
+}
+
+func (s *Service) listObjects(ctx context.Context, input *ListObjectsInput, fn func(ListEntry) error, optFns []Option) (*ListObjectsOutput, error) {
+	output := ListObjectsOutput{}
+
+	var handlers elementHandlers
+	if fn != nil {
+		handlers = listEntryHandlers(&output.Payload.EncodingType, fn)
+	}
+
+	query := url.Values{}
+	setStringQuery(query, "delimiter", input.Delimiter)
+	setStringQuery(query, "encoding-type", input.EncodingType)
+	setStringQuery(query, "marker", input.Marker)
+	setInt32Query(query, "max-keys", input.MaxKeys)
+	setStringQuery(query, "prefix", input.Prefix)
+
+	req, res, err := s.withOptions(optFns).doStreamingCall(
+		ctx,
+		&operation{
+			method: http.MethodGet,
+			bucket: &input.Bucket,
+			query:  query,
+		},
+		&output.Payload,
+		handlers,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	p := &output.Payload
+	err = decodeURLEncoded(p.EncodingType, []*string{p.Prefix, p.Delimiter, p.Marker, p.NextMarker}, p.Contents, p.CommonPrefixes)
+	if err != nil {
+		return nil, err
+	}
+
+	output.HTTPRequest = req
+	output.HTTPResponse = res
+
+	return &output, nil
+}
+
+// ListObjectsAPIClient is the operation used by the ListObjectsPaginator.
+type ListObjectsAPIClient interface {
+	ListObjects(context.Context, *ListObjectsInput, ...Option) (*ListObjectsOutput, error)
+}
+
+var _ ListObjectsAPIClient = (*Service)(nil)
+
+// ListObjectsPaginator walks the pages of a version 1 listing. The next
+// marker is NextMarker when S3 returns it, and the last key or common prefix
+// of the page otherwise.
+type ListObjectsPaginator struct {
+	client  ListObjectsAPIClient
+	input   ListObjectsInput
+	optFns  []Option
+	started bool
+}
+
+func NewListObjectsPaginator(client ListObjectsAPIClient, input *ListObjectsInput, optFns ...Option) *ListObjectsPaginator {
+	return &ListObjectsPaginator{
+		client: client,
+		input:  *input,
+		optFns: optFns,
+	}
+}
+
+func (p *ListObjectsPaginator) HasMorePages() bool {
+	return !p.started || p.input.Marker != nil
+}
+
+func (p *ListObjectsPaginator) NextPage(ctx context.Context) (*ListObjectsOutput, error) {
+	output, err := p.client.ListObjects(ctx, &p.input, p.optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := p.input.Marker
+	p.started = true
+	p.input.Marker = nil
+
+	if !output.Payload.IsTruncated {
+		return output, nil
+	}
+
+	next := deref(output.Payload.NextMarker)
+	if next == "" {
+		entries := listEntries(output.Payload.Contents, output.Payload.CommonPrefixes)
+		if len(entries) > 0 {
+			next = entries[len(entries)-1].Key()
+		}
+	}
+
+	if next != "" && next != deref(previous) {
+		p.input.Marker = &next
+	}
+
+	return output, nil
+}
+
+// All iterates over the objects and common prefixes of the remaining pages.
+// A failed page is yielded as an error, which ends the iteration. It has the
+// signature of an iter.Seq2[ListEntry, error].
+func (p *ListObjectsPaginator) All(ctx context.Context) func(yield func(ListEntry, error) bool) {
+	return func(yield func(ListEntry, error) bool) {
+		for p.HasMorePages() {
+			page, err := p.NextPage(ctx)
+			if err != nil {
+				yield(ListEntry{}, err)
+				return
+			}
+
+			for _, entry := range listEntries(page.Payload.Contents, page.Payload.CommonPrefixes) {
+				if !yield(entry, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Objects iterates over the objects of the remaining pages, skipping the
+// common prefixes. It has the signature of an iter.Seq2[types.Object, error].
+func (p *ListObjectsPaginator) Objects(ctx context.Context) func(yield func(types.Object, error) bool) {
+	return objectsOf(p.All(ctx))
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+type ListObjectsV2Input struct {
+	Bucket string
+
+	ContinuationToken *string
+	Delimiter         *string
+	// EncodingType set to EncodingTypeURL lists keys with any character.
+	EncodingType *string
+	FetchOwner   bool
+	MaxKeys      *int32
+	Prefix       *string
+	StartAfter   *string
+}
+
+type ListObjectsV2Output struct {
+	// Payload holds the decoded keys, even when EncodingType is
+	// EncodingTypeURL.
+	Payload types.ListBucketResultV2
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) ListObjectsV2(ctx context.Context, input *ListObjectsV2Input, optFns ...Option) (*ListObjectsV2Output, error) {
+	return s.listObjectsV2(ctx, input, nil, optFns)
+}
+
+// ListObjectsV2Stream is ListObjectsV2 calling fn with each object and common
+// prefix as soon as it is parsed, in the order of the response. They are not
+// kept in the payload, so that the memory used does not grow with the page
+// size. An error returned by fn stops the listing and is returned.
+func (s *Service) ListObjectsV2Stream(ctx context.Context, input *ListObjectsV2Input, fn func(ListEntry) error, optFns ...Option) (*ListObjectsV2Output, error) {
+	return s.listObjectsV2(ctx, input, fn, optFns)
+}
+
+func (s *Service) listObjectsV2(ctx context.Context, input *ListObjectsV2Input, fn func(ListEntry) error, optFns []Option) (*ListObjectsV2Output, error) {
+	output := ListObjectsV2Output{}
+
+	var handlers elementHandlers
+	if fn != nil {
+		handlers = listEntryHandlers(&output.Payload.EncodingType, fn)
+	}
+
+	query := url.Values{"list-type": []string{"2"}}
+	setStringQuery(query, "continuation-token", input.ContinuationToken)
+	setStringQuery(query, "delimiter", input.Delimiter)
+	setStringQuery(query, "encoding-type", input.EncodingType)
+	if input.FetchOwner {
+		query.Set("fetch-owner", "true")
+	}
+	setInt32Query(query, "max-keys", input.MaxKeys)
+	setStringQuery(query, "prefix", input.Prefix)
+	setStringQuery(query, "start-after", input.StartAfter)
+
+	req, res, err := s.withOptions(optFns).doStreamingCall(
+		ctx,
+		&operation{
+			method: http.MethodGet,
+			bucket: &input.Bucket,
+			query:  query,
+		},
+		&output.Payload,
+		handlers,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	p := &output.Payload
+	err = decodeURLEncoded(p.EncodingType, []*string{p.Prefix, p.Delimiter, p.StartAfter}, p.Contents, p.CommonPrefixes)
+	if err != nil {
+		return nil, err
+	}
+
+	output.HTTPRequest = req
+	output.HTTPResponse = res
+
+	return &output, nil
+}
+
+// ListObjectsV2APIClient is the operation used by the ListObjectsV2Paginator.
+type ListObjectsV2APIClient interface {
+	ListObjectsV2(context.Context, *ListObjectsV2Input, ...Option) (*ListObjectsV2Output, error)
+}
+
+var _ ListObjectsV2APIClient = (*Service)(nil)
+
+// ListObjectsV2Paginator walks the pages of a listing, following the
+// continuation tokens.
+type ListObjectsV2Paginator struct {
+	client  ListObjectsV2APIClient
+	input   ListObjectsV2Input
+	optFns  []Option
+	started bool
+}
+
+func NewListObjectsV2Paginator(client ListObjectsV2APIClient, input *ListObjectsV2Input, optFns ...Option) *ListObjectsV2Paginator {
+	return &ListObjectsV2Paginator{
+		client: client,
+		input:  *input,
+		optFns: optFns,
+	}
+}
+
+func (p *ListObjectsV2Paginator) HasMorePages() bool {
+	return !p.started || p.input.ContinuationToken != nil
+}
+
+func (p *ListObjectsV2Paginator) NextPage(ctx context.Context) (*ListObjectsV2Output, error) {
+	output, err := p.client.ListObjectsV2(ctx, &p.input, p.optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := p.input.ContinuationToken
+	p.started = true
+	p.input.ContinuationToken = nil
+
+	next := output.Payload.NextContinuationToken
+	if output.Payload.IsTruncated && next != nil && *next != "" && (previous == nil || *next != *previous) {
+		p.input.ContinuationToken = next
+	}
+
+	return output, nil
+}
+
+// All iterates over the objects and common prefixes of the remaining pages.
+// A failed page is yielded as an error, which ends the iteration. It has the
+// signature of an iter.Seq2[ListEntry, error].
+func (p *ListObjectsV2Paginator) All(ctx context.Context) func(yield func(ListEntry, error) bool) {
+	return func(yield func(ListEntry, error) bool) {
+		for p.HasMorePages() {
+			page, err := p.NextPage(ctx)
+			if err != nil {
+				yield(ListEntry{}, err)
+				return
+			}
+
+			for _, entry := range listEntries(page.Payload.Contents, page.Payload.CommonPrefixes) {
+				if !yield(entry, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Objects iterates over the objects of the remaining pages, skipping the
+// common prefixes. It has the signature of an iter.Seq2[types.Object, error].
+func (p *ListObjectsV2Paginator) Objects(ctx context.Context) func(yield func(types.Object, error) bool) {
+	return objectsOf(p.All(ctx))
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListObjectsV2(t *testing.T) {
+	expected := types.ListBucketResultV2{
+		Name:                  aws.String("myBucket"),
+		Prefix:                aws.String("photos/"),
+		StartAfter:            aws.String("photos/2005"),
+		ContinuationToken:     aws.String("1ueGcxLPRx1Tr/XYExHnhbYLgveDs2J/wm36Hy4vbOwM="),
+		NextContinuationToken: aws.String("1w41l63U0xa8q7smH50vCxyTQqdxo69O3EmK28Bi5PcROI4wI/EyIJg=="),
+		KeyCount:              2,
+		MaxKeys:               2,
+		Delimiter:             aws.String("/"),
+		IsTruncated:           true,
+		Contents: []types.Object{
+			{
+				Key:          aws.String("photos/index.html"),
+				LastModified: aws.String("2009-10-12T17:50:30Z"),
+				ETag:         aws.String(`"fba9dede5f27731c9771645a39863328"`),
+				Size:         434234,
+				StorageClass: aws.String("STANDARD"),
+				Owner: &types.Owner{
+					DisplayName: aws.String("Account+Name"),
+					ID:          aws.String("DUMMYACKCEVSQ6C2EXAMPLE"),
+				},
+			},
+		},
+		CommonPrefixes: []types.CommonPrefix{
+			{Prefix: aws.String("photos/2006/")},
+		},
+	}
+
+	xmlHandler := NewSimpleXMLResponseHandler(t, &expected)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/myBucket", r.URL.Path)
+
+		query := r.URL.Query()
+		require.Equal(t, "2", query.Get("list-type"))
+		require.Equal(t, *expected.ContinuationToken, query.Get("continuation-token"))
+		require.Equal(t, "/", query.Get("delimiter"))
+		require.Equal(t, "true", query.Get("fetch-owner"))
+		require.Equal(t, "2", query.Get("max-keys"))
+		require.Equal(t, "photos/", query.Get("prefix"))
+		require.Equal(t, "photos/2005", query.Get("start-after"))
+
+		xmlHandler(w, r)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.ListObjectsV2(context.Background(), &ListObjectsV2Input{
+			Bucket:            bucket,
+			ContinuationToken: expected.ContinuationToken,
+			Delimiter:         aws.String("/"),
+			FetchOwner:        true,
+			MaxKeys:           aws.Int32(2),
+			Prefix:            aws.String("photos/"),
+			StartAfter:        aws.String("photos/2005"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, output.Payload)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{
+			Bucket:            &bucket,
+			ContinuationToken: expected.ContinuationToken,
+			Delimiter:         aws.String("/"),
+			FetchOwner:        true,
+			MaxKeys:           2,
+			Prefix:            aws.String("photos/"),
+			StartAfter:        aws.String("photos/2005"),
+		})
+		require.NoError(t, err)
+
+		s3out.ResultMetadata = middleware.Metadata{}
+		require.Equal(t, expected.ToAWS(t), s3out)
+	})
+}
+
+func TestListObjectsV2URLEncoded(t *testing.T) {
+	handler := NewSimpleXMLResponseHandler(t, &types.ListBucketResultV2{
+		Name:         aws.String("myBucket"),
+		Prefix:       aws.String("a+b%2F"),
+		EncodingType: aws.String(EncodingTypeURL),
+		Contents: []types.Object{
+			{Key: aws.String("a+b%2Fc%2Bd%C3%A9%0A")},
+		},
+		CommonPrefixes: []types.CommonPrefix{
+			{Prefix: aws.String("a+b%2F%01%2F")},
+		},
+	})
+
+	ts, ourClient, _ := NewServer(t, handler)
+	defer ts.Close()
+
+	output, err := ourClient.ListObjectsV2(context.Background(), &ListObjectsV2Input{
+		Bucket:       "myBucket",
+		EncodingType: aws.String(EncodingTypeURL),
+	})
+	require.NoError(t, err)
+	require.Equal(t, aws.String("a b/"), output.Payload.Prefix)
+	require.Equal(t, aws.String("a b/c+dé\n"), output.Payload.Contents[0].Key)
+	require.Equal(t, aws.String("a b/\x01/"), output.Payload.CommonPrefixes[0].Prefix)
+}
+
+// listObjectsV2Pages serves the pages in order, chained by continuation
+// tokens.
+type listObjectsV2Pages struct {
+	pages []types.ListBucketResultV2
+	err   error
+	calls []*ListObjectsV2Input
+}
+
+func (l *listObjectsV2Pages) ListObjectsV2(_ context.Context, input *ListObjectsV2Input, _ ...Option) (*ListObjectsV2Output, error) {
+	copied := *input
+	l.calls = append(l.calls, &copied)
+
+	index := 0
+	if input.ContinuationToken != nil {
+		index = int((*input.ContinuationToken)[0] - '0')
+	}
+
+	if index == len(l.pages) {
+		return nil, l.err
+	}
+
+	page := l.pages[index]
+	if index+1 < len(l.pages) || l.err != nil {
+		page.IsTruncated = true
+		page.NextContinuationToken = aws.String(string(rune('0' + index + 1)))
+	}
+
+	return &ListObjectsV2Output{Payload: page}, nil
+}
+
+func objects(keys ...string) []types.Object {
+	result := make([]types.Object, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, types.Object{Key: aws.String(key)})
+	}
+
+	return result
+}
+
+func prefixes(values ...string) []types.CommonPrefix {
+	result := make([]types.CommonPrefix, 0, len(values))
+	for _, value := range values {
+		result = append(result, types.CommonPrefix{Prefix: aws.String(value)})
+	}
+
+	return result
+}
+
+func TestListObjectsV2Paginator(t *testing.T) {
+	pages := []types.ListBucketResultV2{
+		{Contents: objects("a", "c"), CommonPrefixes: prefixes("b/")},
+		{Contents: objects("e"), CommonPrefixes: prefixes("d/", "f/")},
+		{Contents: objects("g")},
+	}
+
+	t.Run("Pages", func(t *testing.T) {
+		client := &listObjectsV2Pages{pages: pages}
+		paginator := NewListObjectsV2Paginator(client, &ListObjectsV2Input{Bucket: "myBucket", Prefix: aws.String("p")})
+
+		count := 0
+		for paginator.HasMorePages() {
+			_, err := paginator.NextPage(context.Background())
+			require.NoError(t, err)
+			count++
+		}
+		require.Equal(t, 3, count)
+
+		require.Nil(t, client.calls[0].ContinuationToken)
+		require.Equal(t, aws.String("2"), client.calls[2].ContinuationToken)
+		require.Equal(t, aws.String("p"), client.calls[2].Prefix)
+	})
+
+	t.Run("All", func(t *testing.T) {
+		paginator := NewListObjectsV2Paginator(&listObjectsV2Pages{pages: pages}, &ListObjectsV2Input{Bucket: "myBucket"})
+
+		var keys []string
+		paginator.All(context.Background())(func(entry ListEntry, err error) bool {
+			require.NoError(t, err)
+			keys = append(keys, entry.Key())
+			return true
+		})
+		require.Equal(t, []string{"a", "b/", "c", "d/", "e", "f/", "g"}, keys)
+	})
+
+	t.Run("Objects", func(t *testing.T) {
+		client := &listObjectsV2Pages{pages: pages}
+		paginator := NewListObjectsV2Paginator(client, &ListObjectsV2Input{Bucket: "myBucket"})
+
+		var keys []string
+		paginator.Objects(context.Background())(func(object types.Object, err error) bool {
+			require.NoError(t, err)
+			keys = append(keys, *object.Key)
+			return len(keys) < 3
+		})
+		require.Equal(t, []string{"a", "c", "e"}, keys)
+		require.Len(t, client.calls, 2)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		failure := errors.New("failure")
+		paginator := NewListObjectsV2Paginator(&listObjectsV2Pages{pages: pages[:1], err: failure}, &ListObjectsV2Input{Bucket: "myBucket"})
+
+		var keys []string
+		var errs []error
+		paginator.All(context.Background())(func(entry ListEntry, err error) bool {
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				keys = append(keys, entry.Key())
+			}
+			return true
+		})
+		require.Equal(t, []string{"a", "b/", "c"}, keys)
+		require.Equal(t, []error{failure}, errs)
+	})
+
+	t.Run("RepeatedToken", func(t *testing.T) {
+		client := &listObjectsV2Pages{pages: pages}
+		paginator := NewListObjectsV2Paginator(client, &ListObjectsV2Input{Bucket: "myBucket", ContinuationToken: aws.String("1")})
+
+		client.pages = []types.ListBucketResultV2{{}, {IsTruncated: true, NextContinuationToken: aws.String("1")}}
+		client.err = nil
+		_, err := paginator.NextPage(context.Background())
+		require.NoError(t, err)
+		require.False(t, paginator.HasMorePages())
+	})
+}
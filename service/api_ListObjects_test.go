@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListObjects(t *testing.T) {
+	expected := types.ListBucketResult{
+		Name:        aws.String("myBucket"),
+		Prefix:      aws.String("photos/"),
+		Marker:      aws.String("photos/2005"),
+		NextMarker:  aws.String("photos/2006/"),
+		MaxKeys:     2,
+		Delimiter:   aws.String("/"),
+		IsTruncated: true,
+		Contents: []types.Object{
+			{
+				Key:          aws.String("photos/index.html"),
+				LastModified: aws.String("2009-10-12T17:50:30Z"),
+				ETag:         aws.String(`"fba9dede5f27731c9771645a39863328"`),
+				Size:         434234,
+				StorageClass: aws.String("STANDARD"),
+			},
+		},
+		CommonPrefixes: []types.CommonPrefix{
+			{Prefix: aws.String("photos/2006/")},
+		},
+	}
+
+	xmlHandler := NewSimpleXMLResponseHandler(t, &expected)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/myBucket", r.URL.Path)
+
+		query := r.URL.Query()
+		require.False(t, query.Has("list-type"))
+		require.Equal(t, "photos/2005", query.Get("marker"))
+		require.Equal(t, "/", query.Get("delimiter"))
+		require.Equal(t, "2", query.Get("max-keys"))
+		require.Equal(t, "photos/", query.Get("prefix"))
+
+		xmlHandler(w, r)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.ListObjects(context.Background(), &ListObjectsInput{
+			Bucket:    bucket,
+			Delimiter: aws.String("/"),
+			Marker:    aws.String("photos/2005"),
+			MaxKeys:   aws.Int32(2),
+			Prefix:    aws.String("photos/"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, output.Payload)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.ListObjects(context.Background(), &s3.ListObjectsInput{
+			Bucket:    &bucket,
+			Delimiter: aws.String("/"),
+			Marker:    aws.String("photos/2005"),
+			MaxKeys:   2,
+			Prefix:    aws.String("photos/"),
+		})
+		require.NoError(t, err)
+
+		s3out.ResultMetadata = middleware.Metadata{}
+		require.Equal(t, expected.ToAWS(t), s3out)
+	})
+}
+
+// listObjectsPages serves the pages in order, chained by the marker. Like
+// S3 without a delimiter, it does not return NextMarker.
+type listObjectsPages struct {
+	pages []types.ListBucketResult
+	calls []*ListObjectsInput
+}
+
+func (l *listObjectsPages) ListObjects(_ context.Context, input *ListObjectsInput, _ ...Option) (*ListObjectsOutput, error) {
+	copied := *input
+	l.calls = append(l.calls, &copied)
+
+	for _, page := range l.pages {
+		entries := listEntries(page.Contents, page.CommonPrefixes)
+		if input.Marker == nil || entries[0].Key() > *input.Marker {
+			return &ListObjectsOutput{Payload: page}, nil
+		}
+	}
+
+	return &ListObjectsOutput{}, nil
+}
+
+func TestListObjectsPaginator(t *testing.T) {
+	client := &listObjectsPages{pages: []types.ListBucketResult{
+		{IsTruncated: true, Contents: objects("a", "b")},
+		{IsTruncated: true, Contents: objects("c"), CommonPrefixes: prefixes("d/")},
+		{IsTruncated: true, Contents: objects("e"), NextMarker: aws.String("e")},
+		{Contents: objects("f")},
+	}}
+
+	paginator := NewListObjectsPaginator(client, &ListObjectsInput{Bucket: "myBucket"})
+
+	var keys []string
+	paginator.All(context.Background())(func(entry ListEntry, err error) bool {
+		require.NoError(t, err)
+		keys = append(keys, entry.Key())
+		return true
+	})
+	require.Equal(t, []string{"a", "b", "c", "d/", "e", "f"}, keys)
+
+	markers := []*string{}
+	for _, call := range client.calls {
+		markers = append(markers, call.Marker)
+	}
+	require.Equal(t, []*string{nil, aws.String("b"), aws.String("d/"), aws.String("e")}, markers)
+	require.False(t, paginator.HasMorePages())
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+type ListPartsInput struct {
+	Bucket   string
+	Key      string
+	UploadID string
+
+	MaxParts         *int32
+	PartNumberMarker *string
+}
+
+type ListPartsOutput struct {
+	Payload types.ListPartsResult
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) ListParts(ctx context.Context, input *ListPartsInput, optFns ...Option) (*ListPartsOutput, error) {
+	return s.listParts(ctx, input, nil, optFns)
+}
+
+// ListPartsStream is ListParts calling fn with each part as soon as it is
+// parsed. The parts are not kept in the payload, so that the memory used does
+// not grow with the page size. An error returned by fn stops the listing and
+// is returned.
+func (s *Service) ListPartsStream(ctx context.Context, input *ListPartsInput, fn func(types.Part) error, optFns ...Option) (*ListPartsOutput, error) {
+	return s.listParts(ctx, input, elementHandlers{
+		"Part": func(d *xml.Decoder, start *xml.StartElement) error {
+			var part types.Part
+			if err := d.DecodeElement(&part, start); err != nil {
+				return err
+			}
+
+			return fn(part)
+		},
+	}, optFns)
+}
+
+func (s *Service) listParts(ctx context.Context, input *ListPartsInput, handlers elementHandlers, optFns []Option) (*ListPartsOutput, error) {
+	output := ListPartsOutput{}
+
+	query := uploadQuery(input.UploadID)
+	setInt32Query(query, "max-parts", input.MaxParts)
+	setStringQuery(query, "part-number-marker", input.PartNumberMarker)
+
+	req, res, err := s.withOptions(optFns).doStreamingCall(
+		ctx,
+		&operation{
+			method: http.MethodGet,
+			bucket: &input.Bucket,
+			key:    &input.Key,
+			query:  query,
+		},
+		&output.Payload,
+		handlers,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	output.HTTPRequest = req
+	output.HTTPResponse = res
+
+	return &output, nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListParts(t *testing.T) {
+	expected := types.ListPartsResult{
+		Bucket:   aws.String("myBucket"),
+		Key:      aws.String("large.bin"),
+		UploadID: aws.String("upload"),
+		Initiator: &types.Initiator{
+			DisplayName: aws.String("umat-user-11116a31-17b5-4fb7-9df5-b288870f11xx"),
+			ID:          aws.String("arn:aws:iam::111122223333:user/some-user-11116a31-17b5-4fb7-9df5-b288870f11xx"),
+		},
+		Owner: &types.Owner{
+			DisplayName: aws.String("Account+Name"),
+			ID:          aws.String("DUMMYACKCEVSQ6C2EXAMPLE"),
+		},
+		StorageClass:         aws.String("STANDARD"),
+		PartNumberMarker:     aws.String("1"),
+		NextPartNumberMarker: aws.String("3"),
+		MaxParts:             2,
+		IsTruncated:          true,
+		Parts: []types.Part{
+			{
+				PartNumber:   2,
+				LastModified: aws.String("2010-11-10T20:48:34Z"),
+				ETag:         aws.String(`"7778aef83f66abc1fa1e8477f296d394"`),
+				Size:         10485760,
+			},
+			{
+				PartNumber:   3,
+				LastModified: aws.String("2010-11-10T20:48:33Z"),
+				ETag:         aws.String(`"aaaa18db4cc2f85cedef654fccc4a4x8"`),
+				Size:         10485760,
+			},
+		},
+	}
+
+	xmlHandler := NewSimpleXMLResponseHandler(t, &expected)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/myBucket/large.bin", r.URL.Path)
+		require.Equal(t, "upload", r.URL.Query().Get("uploadId"))
+		require.Equal(t, "2", r.URL.Query().Get("max-parts"))
+		require.Equal(t, "1", r.URL.Query().Get("part-number-marker"))
+
+		xmlHandler(w, r)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "large.bin"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.ListParts(context.Background(), &ListPartsInput{
+			Bucket:           bucket,
+			Key:              key,
+			UploadID:         "upload",
+			MaxParts:         aws.Int32(2),
+			PartNumberMarker: aws.String("1"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, output.Payload)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.ListParts(context.Background(), &s3.ListPartsInput{
+			Bucket:           &bucket,
+			Key:              &key,
+			UploadId:         aws.String("upload"),
+			MaxParts:         2,
+			PartNumberMarker: aws.String("1"),
+		})
+		require.NoError(t, err)
+
+		s3out.ResultMetadata = middleware.Metadata{}
+		require.Equal(t, expected.ToAWS(t), s3out)
+	})
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sort"
+)
+
+type PostObjectInput struct {
+	Bucket string
+
+	// Fields are the form fields, such as the ones of a PresignedPost, along
+	// with the key and the fields the policy only constrains by a prefix.
+	Fields map[string]string
+
+	// FileName is the name of the file part, which S3 substitutes to
+	// ${filename} in the key.
+	FileName string
+
+	// Body is sent as the file part. A nil Body uploads an empty object.
+	Body io.Reader
+
+	// ContentLength is the size of Body. When nil it is guessed from the
+	// concrete type of Body, or Body is read in memory.
+	ContentLength *int64
+}
+
+type PostObjectOutput struct {
+	ETag     *string
+	Location *string
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+// PostObject uploads an object with a browser-based POST form. The call is
+// not signed: the policy signature of the fields authenticates it.
+func (s *Service) PostObject(ctx context.Context, input *PostObjectInput, optFns ...Option) (*PostObjectOutput, error) {
+	body, contentType, contentLength, err := newPostObjectBody(input)
+	if err != nil {
+		return nil, err
+	}
+
+	optFns = append(append([]Option{}, optFns...), WithAnonymous())
+
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodPost,
+			bucket: &input.Bucket,
+			header: http.Header{"Content-Type": {contentType}},
+			body:   body,
+
+			contentLength: contentLength,
+		},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostObjectOutput{
+		ETag:         getStringHeader(res.Header, "ETag"),
+		Location:     getStringHeader(res.Header, "Location"),
+		HTTPRequest:  req,
+		HTTPResponse: res,
+	}, nil
+}
+
+// newPostObjectBody streams the multipart form: the fields, then the file part
+// which S3 requires last. The length is known without buffering the file when
+// the one of Body is.
+func newPostObjectBody(input *PostObjectInput) (io.Reader, string, int64, error) {
+	var head bytes.Buffer
+	w := multipart.NewWriter(&head)
+
+	names := make([]string, 0, len(input.Fields))
+	for name := range input.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := w.WriteField(name, input.Fields[name]); err != nil {
+			return nil, "", 0, err
+		}
+	}
+
+	fileName := input.FileName
+	if fileName == "" {
+		fileName = "file"
+	}
+
+	if _, err := w.CreateFormFile("file", fileName); err != nil {
+		return nil, "", 0, err
+	}
+
+	tail := fmt.Sprintf("\r\n--%s--\r\n", w.Boundary())
+
+	file := input.Body
+	if file == nil {
+		file = http.NoBody
+	}
+
+	fileLength := bodyLength(input.Body)
+	if input.Body == nil {
+		fileLength = 0
+	}
+	if input.ContentLength != nil {
+		fileLength = *input.ContentLength
+	}
+
+	if fileLength < 0 {
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return nil, "", 0, fmt.Errorf("cannot read the post object body: %w", err)
+		}
+
+		file = bytes.NewReader(content)
+		fileLength = int64(len(content))
+	}
+
+	body := io.MultiReader(&head, file, bytes.NewReader([]byte(tail)))
+	length := int64(head.Len()) + fileLength + int64(len(tail))
+
+	return body, w.FormDataContentType(), length, nil
+}
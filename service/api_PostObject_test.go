@@ -0,0 +1,126 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	signv4 "github.com/lvjp/raw-s3-sdk-go/signing/v4"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostObject(t *testing.T) {
+	const content = "<html>hello</html>"
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/myBucket", r.URL.Path)
+		require.Empty(t, r.Header.Get("Authorization"))
+		require.NotEqual(t, int64(-1), r.ContentLength)
+
+		reader, err := r.MultipartReader()
+		require.NoError(t, err)
+
+		fields := map[string]string{}
+		for {
+			part, err := reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+
+			value, err := io.ReadAll(part)
+			require.NoError(t, err)
+
+			if part.FormName() == "file" {
+				require.Equal(t, "index.html", part.FileName())
+				require.Equal(t, content, string(value))
+
+				// S3 ignores the fields after the file.
+				_, err = reader.NextPart()
+				require.ErrorIs(t, err, io.EOF)
+				break
+			}
+
+			fields[part.FormName()] = string(value)
+		}
+
+		require.Equal(t, "uploads/${filename}", fields["key"])
+		require.Equal(t, "text/html", fields["Content-Type"])
+		require.Len(t, fields["x-amz-signature"], 64)
+
+		policy, err := base64.StdEncoding.DecodeString(fields["policy"])
+		require.NoError(t, err)
+		require.Contains(t, string(policy), `{"bucket":"myBucket"}`)
+		require.Contains(t, string(policy), `["starts-with","$key","uploads/"]`)
+
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Location", "http://myBucket.example.com/uploads/index.html")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ts, ourClient, _ := NewServer(t, handler)
+	defer ts.Close()
+
+	policy := signv4.NewPostPolicy(time.Now().Add(time.Hour)).
+		SetBucket("myBucket").
+		SetKeyStartsWith("uploads/").
+		SetContentType("text/html").
+		SetContentLengthRange(0, 1024)
+
+	presigned, err := ourClient.PresignPost(context.Background(), "myBucket", policy)
+	require.NoError(t, err)
+	require.Equal(t, ts.URL+"/myBucket", presigned.URL)
+
+	fields := presigned.Fields
+	fields["key"] = "uploads/${filename}"
+
+	bodies := map[string]io.Reader{
+		"KnownLength":   strings.NewReader(content),
+		"UnknownLength": io.MultiReader(bytes.NewBufferString(content)),
+	}
+
+	for name, body := range bodies {
+		t.Run(name, func(t *testing.T) {
+			output, err := ourClient.PostObject(context.Background(), &PostObjectInput{
+				Bucket:   "myBucket",
+				Fields:   fields,
+				FileName: "index.html",
+				Body:     body,
+			})
+			require.NoError(t, err)
+			require.Equal(t, `"etag"`, *output.ETag)
+			require.Equal(t, "http://myBucket.example.com/uploads/index.html", *output.Location)
+		})
+	}
+}
+
+func TestPostObjectError(t *testing.T) {
+	ts, ourClient, _ := NewServer(t, NewErrorResponseHandler(t, http.StatusForbidden, &types.Error{
+		Code:    "AccessDenied",
+		Message: "Invalid according to Policy: Policy Condition failed",
+	}))
+	defer ts.Close()
+
+	_, err := ourClient.PostObject(context.Background(), &PostObjectInput{
+		Bucket: "myBucket",
+		Fields: map[string]string{"key": "other/key"},
+		Body:   strings.NewReader("content"),
+	})
+	require.ErrorIs(t, err, ErrAccessDenied)
+}
+
+func TestPresignPostRequiresV4(t *testing.T) {
+	ts, ourClient, _ := NewServer(t, http.NotFound)
+	defer ts.Close()
+
+	_, err := ourClient.PresignPost(context.Background(), "myBucket", signv4.NewPostPolicy(time.Now().Add(time.Hour)), WithAnonymous())
+	require.Error(t, err)
+}
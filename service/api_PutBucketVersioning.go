@@ -0,0 +1,75 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // required by Content-MD5
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+const (
+	BucketVersioningStatusEnabled   = "Enabled"
+	BucketVersioningStatusSuspended = "Suspended"
+
+	MFADeleteEnabled  = "Enabled"
+	MFADeleteDisabled = "Disabled"
+)
+
+type PutBucketVersioningInput struct {
+	Bucket string
+
+	VersioningConfiguration types.VersioningConfiguration
+
+	// MFA is the serial number of the device, a space and the code it
+	// displays. It is required to change MFADelete.
+	MFA *string
+}
+
+type PutBucketVersioningOutput struct {
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+// PutBucketVersioning enables or suspends the versioning of a bucket. The
+// Content-MD5 S3 requires is computed from the configuration.
+func (s *Service) PutBucketVersioning(ctx context.Context, input *PutBucketVersioningInput, optFns ...Option) (*PutBucketVersioningOutput, error) {
+	body, err := xml.Marshal(&input.VersioningConfiguration)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the versioning configuration: %w", err)
+	}
+
+	sum := md5.Sum(body) //nolint:gosec // required by Content-MD5
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml")
+	header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	setStringHeader(header, "X-Amz-Mfa", input.MFA)
+
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodPut,
+			bucket: &input.Bucket,
+			query:  url.Values{"versioning": []string{""}},
+			header: header,
+			body:   bytes.NewReader(body),
+
+			contentLength: int64(len(body)),
+		},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PutBucketVersioningOutput{
+		HTTPRequest:  req,
+		HTTPResponse: res,
+	}, nil
+}
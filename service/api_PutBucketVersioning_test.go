@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // required by Content-MD5
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutBucketVersioning(t *testing.T) {
+	expected := types.VersioningConfiguration{
+		Status:    aws.String(BucketVersioningStatusEnabled),
+		MFADelete: aws.String(MFADeleteEnabled),
+	}
+	mfa := "arn:aws:iam::123456789012:mfa/user 123456"
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "/myBucket", r.URL.Path)
+		require.Contains(t, r.URL.Query(), "versioning")
+		require.Equal(t, mfa, r.Header.Get("X-Amz-Mfa"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		sum := md5.Sum(body) //nolint:gosec // required by Content-MD5
+		require.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), r.Header.Get("Content-MD5"))
+
+		var payload types.VersioningConfiguration
+		require.NoError(t, xml.Unmarshal(body, &payload))
+		require.Equal(t, expected, payload)
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.PutBucketVersioning(context.Background(), &PutBucketVersioningInput{
+			Bucket:                  bucket,
+			VersioningConfiguration: expected,
+			MFA:                     &mfa,
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, output.HTTPResponse.StatusCode)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		_, err := awsClient.PutBucketVersioning(context.Background(), &s3.PutBucketVersioningInput{
+			Bucket: &bucket,
+			MFA:    &mfa,
+			VersioningConfiguration: &s3types.VersioningConfiguration{
+				Status:    s3types.BucketVersioningStatusEnabled,
+				MFADelete: s3types.MFADeleteEnabled,
+			},
+		})
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+type PutObjectInput struct {
+	Bucket string
+	Key    string
+
+	// Body is streamed as the object content. A nil Body creates an empty object.
+	Body io.Reader
+
+	// ContentLength is the size of Body. When nil it is guessed from the
+	// concrete type of Body.
+	ContentLength *int64
+
+	CacheControl       *string
+	ContentDisposition *string
+	ContentEncoding    *string
+	ContentLanguage    *string
+	ContentMD5         *string
+	// ContentSHA256 is the hex-encoded SHA-256 of Body. When set, it is used
+	// as the signed payload hash instead of hashing Body.
+	ContentSHA256 *string
+	ContentType   *string
+	Expires       *time.Time
+	StorageClass  *string
+
+	Metadata map[string]string
+
+	IfMatch     *string
+	IfNoneMatch *string
+}
+
+type PutObjectOutput struct {
+	ETag *string
+	// VersionID is the version created, when the bucket is versioned.
+	VersionID *string
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) PutObject(ctx context.Context, input *PutObjectInput, optFns ...Option) (*PutObjectOutput, error) {
+	header := http.Header{}
+	setStringHeader(header, "Cache-Control", input.CacheControl)
+	setStringHeader(header, "Content-Disposition", input.ContentDisposition)
+	setStringHeader(header, "Content-Encoding", input.ContentEncoding)
+	setStringHeader(header, "Content-Language", input.ContentLanguage)
+	setStringHeader(header, "Content-MD5", input.ContentMD5)
+	setStringHeader(header, contentSHA256Header, input.ContentSHA256)
+	setStringHeader(header, "Content-Type", input.ContentType)
+	setTimeHeader(header, "Expires", input.Expires)
+	setStringHeader(header, "X-Amz-Storage-Class", input.StorageClass)
+	setStringHeader(header, "If-Match", input.IfMatch)
+	setStringHeader(header, "If-None-Match", input.IfNoneMatch)
+	setMetadataHeaders(header, input.Metadata)
+
+	contentLength := bodyLength(input.Body)
+	if input.ContentLength != nil {
+		contentLength = *input.ContentLength
+	}
+
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodPut,
+			bucket: &input.Bucket,
+			key:    &input.Key,
+			header: header,
+			body:   input.Body,
+
+			contentLength: contentLength,
+		},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PutObjectOutput{
+		ETag:         getStringHeader(res.Header, "ETag"),
+		VersionID:    getStringHeader(res.Header, "X-Amz-Version-Id"),
+		HTTPRequest:  req,
+		HTTPResponse: res,
+	}, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutObject(t *testing.T) {
+	const content = "Hello, World!"
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "/myBucket/greeting.txt", r.URL.Path)
+		require.Equal(t, int64(len(content)), r.ContentLength)
+		require.Equal(t, "text/plain", r.Header.Get("Content-Type"))
+		require.Equal(t, "bar", r.Header.Get("X-Amz-Meta-Foo"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, content, string(body))
+
+		w.Header().Set("ETag", `"65a8e27d8879283831b664bd8b7f0ad4"`)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "greeting.txt"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.PutObject(context.Background(), &PutObjectInput{
+			Bucket:      bucket,
+			Key:         key,
+			Body:        strings.NewReader(content),
+			ContentType: aws.String("text/plain"),
+			Metadata:    map[string]string{"foo": "bar"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, aws.String(`"65a8e27d8879283831b664bd8b7f0ad4"`), output.ETag)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.PutObject(context.Background(), &s3.PutObjectInput{
+			Bucket:      &bucket,
+			Key:         &key,
+			Body:        strings.NewReader(content),
+			ContentType: aws.String("text/plain"),
+			Metadata:    map[string]string{"foo": "bar"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, aws.String(`"65a8e27d8879283831b664bd8b7f0ad4"`), s3out.ETag)
+	})
+}
+
+func TestPutObjectStreaming(t *testing.T) {
+	content := strings.Repeat("0123456789abcdef", 5000)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "STREAMING-AWS4-HMAC-SHA256-PAYLOAD", r.Header.Get("X-Amz-Content-Sha256"))
+		require.Equal(t, "aws-chunked", r.Header.Get("Content-Encoding"))
+		require.Equal(t, strconv.Itoa(len(content)), r.Header.Get("X-Amz-Decoded-Content-Length"))
+
+		encoded, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, r.ContentLength, int64(len(encoded)))
+
+		var decoded strings.Builder
+		for {
+			header, rest, found := strings.Cut(string(encoded), "\r\n")
+			require.True(t, found)
+
+			sizeRaw, signature, found := strings.Cut(header, ";chunk-signature=")
+			require.True(t, found)
+			require.Len(t, signature, 64)
+
+			size, err := strconv.ParseInt(sizeRaw, 16, 64)
+			require.NoError(t, err)
+
+			decoded.WriteString(rest[:size])
+			encoded = []byte(rest[size+2:])
+
+			if size == 0 {
+				break
+			}
+		}
+		require.Empty(t, encoded)
+		require.Equal(t, content, decoded.String())
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts, ourClient, _ := NewServer(t, handler)
+	defer ts.Close()
+
+	// Hide the concrete type so that the body is seen as a plain stream.
+	body := io.MultiReader(strings.NewReader(content))
+
+	_, err := ourClient.PutObject(context.Background(), &PutObjectInput{
+		Bucket:        "myBucket",
+		Key:           "stream.bin",
+		Body:          body,
+		ContentLength: aws.Int64(int64(len(content))),
+	})
+	require.NoError(t, err)
+}
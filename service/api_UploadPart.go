@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type UploadPartInput struct {
+	Bucket   string
+	Key      string
+	UploadID string
+
+	// PartNumber is between 1 and 10000.
+	PartNumber int32
+
+	Body io.Reader
+
+	// ContentLength is the size of Body. When nil it is guessed from the
+	// concrete type of Body.
+	ContentLength *int64
+
+	ContentMD5 *string
+	// ContentSHA256 is the hex-encoded SHA-256 of Body. When set, it is used
+	// as the signed payload hash instead of hashing Body.
+	ContentSHA256 *string
+}
+
+type UploadPartOutput struct {
+	ETag *string
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) UploadPart(ctx context.Context, input *UploadPartInput, optFns ...Option) (*UploadPartOutput, error) {
+	header := http.Header{}
+	setStringHeader(header, "Content-MD5", input.ContentMD5)
+	setStringHeader(header, contentSHA256Header, input.ContentSHA256)
+
+	contentLength := bodyLength(input.Body)
+	if input.ContentLength != nil {
+		contentLength = *input.ContentLength
+	}
+
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodPut,
+			bucket: &input.Bucket,
+			key:    &input.Key,
+			query:  partQuery(input.UploadID, input.PartNumber),
+			header: header,
+			body:   input.Body,
+
+			contentLength: contentLength,
+		},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadPartOutput{
+		ETag:         getStringHeader(res.Header, "ETag"),
+		HTTPRequest:  req,
+		HTTPResponse: res,
+	}, nil
+}
+
+func uploadQuery(uploadID string) url.Values {
+	return url.Values{"uploadId": []string{uploadID}}
+}
+
+func partQuery(uploadID string, partNumber int32) url.Values {
+	query := uploadQuery(uploadID)
+	query.Set("partNumber", strconv.FormatInt(int64(partNumber), 10))
+
+	return query
+}
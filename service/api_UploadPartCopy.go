@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+type UploadPartCopyInput struct {
+	Bucket     string
+	Key        string
+	UploadID   string
+	PartNumber int32
+
+	// CopySource is the URL-encoded source object, as "bucket/key".
+	CopySource string
+	// CopySourceRange is the byte range of the source, as "bytes=first-last".
+	CopySourceRange *string
+
+	CopySourceIfMatch           *string
+	CopySourceIfNoneMatch       *string
+	CopySourceIfModifiedSince   *time.Time
+	CopySourceIfUnmodifiedSince *time.Time
+}
+
+type UploadPartCopyOutput struct {
+	Payload types.CopyPartResult
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (s *Service) UploadPartCopy(ctx context.Context, input *UploadPartCopyInput, optFns ...Option) (*UploadPartCopyOutput, error) {
+	output := UploadPartCopyOutput{}
+
+	header := http.Header{}
+	header.Set("X-Amz-Copy-Source", input.CopySource)
+	setStringHeader(header, "X-Amz-Copy-Source-Range", input.CopySourceRange)
+	setStringHeader(header, "X-Amz-Copy-Source-If-Match", input.CopySourceIfMatch)
+	setStringHeader(header, "X-Amz-Copy-Source-If-None-Match", input.CopySourceIfNoneMatch)
+	setTimeHeader(header, "X-Amz-Copy-Source-If-Modified-Since", input.CopySourceIfModifiedSince)
+	setTimeHeader(header, "X-Amz-Copy-Source-If-Unmodified-Since", input.CopySourceIfUnmodifiedSince)
+
+	req, res, err := s.withOptions(optFns).doCall(
+		ctx,
+		&operation{
+			method: http.MethodPut,
+			bucket: &input.Bucket,
+			key:    &input.Key,
+			query:  partQuery(input.UploadID, input.PartNumber),
+			header: header,
+		},
+		&output.Payload,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	output.HTTPRequest = req
+	output.HTTPResponse = res
+
+	return &output, nil
+}
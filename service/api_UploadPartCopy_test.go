@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadPartCopy(t *testing.T) {
+	expected := types.CopyPartResult{
+		ETag:         aws.String(`"b0c6f0e7e054ab8fa2536a2677f8734d"`),
+		LastModified: aws.String("2023-03-01T12:00:00Z"),
+	}
+
+	xmlHandler := NewSimpleXMLResponseHandler(t, &expected)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "/myBucket/large.bin", r.URL.Path)
+		require.Equal(t, "upload", r.URL.Query().Get("uploadId"))
+		require.Equal(t, "1", r.URL.Query().Get("partNumber"))
+		require.Equal(t, "source/object.bin", r.Header.Get("X-Amz-Copy-Source"))
+		require.Equal(t, "bytes=0-5242879", r.Header.Get("X-Amz-Copy-Source-Range"))
+
+		xmlHandler(w, r)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "large.bin"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.UploadPartCopy(context.Background(), &UploadPartCopyInput{
+			Bucket:          bucket,
+			Key:             key,
+			UploadID:        "upload",
+			PartNumber:      1,
+			CopySource:      "source/object.bin",
+			CopySourceRange: aws.String("bytes=0-5242879"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, output.Payload)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.UploadPartCopy(context.Background(), &s3.UploadPartCopyInput{
+			Bucket:          &bucket,
+			Key:             &key,
+			UploadId:        aws.String("upload"),
+			PartNumber:      1,
+			CopySource:      aws.String("source/object.bin"),
+			CopySourceRange: aws.String("bytes=0-5242879"),
+		})
+		require.NoError(t, err)
+
+		s3out.ResultMetadata = middleware.Metadata{}
+		require.Equal(t, expected.ToAWS(t), s3out)
+	})
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadPart(t *testing.T) {
+	const content = "Hello, World!"
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "/myBucket/large.bin", r.URL.Path)
+		require.Equal(t, "upload", r.URL.Query().Get("uploadId"))
+		require.Equal(t, "2", r.URL.Query().Get("partNumber"))
+		require.Equal(t, int64(len(content)), r.ContentLength)
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, content, string(body))
+
+		w.Header().Set("ETag", `"65a8e27d8879283831b664bd8b7f0ad4"`)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts, ourClient, awsClient := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "large.bin"
+
+	t.Run("our", func(t *testing.T) {
+		output, err := ourClient.UploadPart(context.Background(), &UploadPartInput{
+			Bucket:     bucket,
+			Key:        key,
+			UploadID:   "upload",
+			PartNumber: 2,
+			Body:       strings.NewReader(content),
+		})
+		require.NoError(t, err)
+		require.Equal(t, aws.String(`"65a8e27d8879283831b664bd8b7f0ad4"`), output.ETag)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		s3out, err := awsClient.UploadPart(context.Background(), &s3.UploadPartInput{
+			Bucket:     &bucket,
+			Key:        &key,
+			UploadId:   aws.String("upload"),
+			PartNumber: 2,
+			Body:       strings.NewReader(content),
+		})
+		require.NoError(t, err)
+		require.Equal(t, aws.String(`"65a8e27d8879283831b664bd8b7f0ad4"`), s3out.ETag)
+	})
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+type countingProvider struct {
+	calls int
+	creds config.Credentials
+	err   error
+}
+
+func (p *countingProvider) Retrieve(ctx context.Context) (config.Credentials, error) {
+	p.calls++
+	return p.creds, p.err
+}
+
+func TestCredentialsProvider(t *testing.T) {
+	var authorization string
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts, ourClient, _ := NewServer(t, handler)
+	defer ts.Close()
+
+	provider := &countingProvider{creds: config.Credentials{AccessKey: "PROVIDED", SecretKey: "SECRET"}}
+	ourClient.config.Credentials = provider
+
+	_, err := ourClient.HeadBucket(context.Background(), "myBucket")
+	require.NoError(t, err)
+	require.Equal(t, 1, provider.calls)
+	require.Contains(t, authorization, "Credential=PROVIDED/")
+
+	_, err = ourClient.HeadBucket(context.Background(), "myBucket", WithAnonymous())
+	require.NoError(t, err)
+	require.Equal(t, 1, provider.calls, "anonymous calls do not need credentials")
+
+	errExpired := errors.New("expired")
+	provider.err = errExpired
+	_, err = ourClient.HeadBucket(context.Background(), "myBucket")
+	require.ErrorIs(t, err, errExpired)
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+// elementHandlers are called with the decoder positioned on a child of the
+// root element, keyed by local name. A handler must consume the element, up
+// to its end, typically with DecodeElement.
+type elementHandlers map[string]func(d *xml.Decoder, start *xml.StartElement) error
+
+// decodeBody decodes the response body while it is read, so that the
+// elements streamed to the handlers are never all held in memory.
+func decodeBody(req *http.Request, res *http.Response, payload any, handlers elementHandlers) error {
+	raw := xml.NewDecoder(res.Body)
+
+	decoder := raw
+	if len(handlers) > 0 {
+		decoder = xml.NewTokenDecoder(&streamingTokenReader{decoder: raw, handlers: handlers})
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if start.Name.Local == "Error" {
+			var errorPayload types.Error
+			if err := decoder.DecodeElement(&errorPayload, &start); err != nil {
+				return err
+			}
+
+			return newResponseErrorFromPayload(req, res, errorPayload)
+		}
+
+		return decoder.DecodeElement(payload, &start)
+	}
+}
+
+// streamingTokenReader hides the children of the root element named in the
+// handlers from the decoder of the payload, handing them to the handlers
+// instead.
+type streamingTokenReader struct {
+	decoder  *xml.Decoder
+	handlers elementHandlers
+	depth    int
+}
+
+func (r *streamingTokenReader) Token() (xml.Token, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if handler, ok := r.handlers[t.Name.Local]; ok && r.depth == 1 {
+				if err := handler(r.decoder, &t); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			r.depth++
+		case xml.EndElement:
+			r.depth--
+		}
+
+		return token, nil
+	}
+}
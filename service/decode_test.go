@@ -0,0 +1,287 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newListBucketResultV2 returns a listing page of the given number of
+// objects, as sent by S3.
+func newListBucketResultV2(count int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`)
+	buf.WriteString(`<Name>myBucket</Name><Prefix></Prefix>`)
+	fmt.Fprintf(&buf, `<KeyCount>%d</KeyCount><MaxKeys>%d</MaxKeys><IsTruncated>false</IsTruncated>`, count, count)
+
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(
+			&buf,
+			`<Contents><Key>photos/2023/%08d.jpg</Key><LastModified>2023-03-01T12:00:00.000Z</LastModified>`+
+				`<ETag>&quot;fba9dede5f27731c9771645a39863328&quot;</ETag><Size>434234</Size>`+
+				`<StorageClass>STANDARD</StorageClass></Contents>`,
+			i,
+		)
+	}
+
+	buf.WriteString(`<CommonPrefixes><Prefix>photos/2024/</Prefix></CommonPrefixes>`)
+	buf.WriteString(`</ListBucketResult>`)
+
+	return buf.Bytes()
+}
+
+func newResponse(body []byte) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	body := newListBucketResultV2(3)
+
+	var expected types.ListBucketResultV2
+	require.NoError(t, xml.Unmarshal(body, &expected))
+
+	t.Run("Payload", func(t *testing.T) {
+		var payload types.ListBucketResultV2
+		require.NoError(t, decodeBody(nil, newResponse(body), &payload, nil))
+		require.Equal(t, expected, payload)
+	})
+
+	t.Run("Streaming", func(t *testing.T) {
+		var entries []ListEntry
+		var payload types.ListBucketResultV2
+		err := decodeBody(nil, newResponse(body), &payload, listEntryHandlers(&payload.EncodingType, func(entry ListEntry) error {
+			entries = append(entries, entry)
+			return nil
+		}))
+		require.NoError(t, err)
+
+		require.Equal(t, listEntries(expected.Contents, expected.CommonPrefixes), entries)
+
+		expected.Contents = nil
+		expected.CommonPrefixes = nil
+		require.Equal(t, expected, payload)
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		stop := errors.New("stop")
+
+		count := 0
+		var payload types.ListBucketResultV2
+		err := decodeBody(nil, newResponse(body), &payload, listEntryHandlers(&payload.EncodingType, func(entry ListEntry) error {
+			count++
+			return stop
+		}))
+		require.ErrorIs(t, err, stop)
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("EmbeddedError", func(t *testing.T) {
+		res := newResponse([]byte(xml.Header + `<Error><Code>InternalError</Code><RequestId>42</RequestId></Error>`))
+
+		var payload types.ListBucketResultV2
+		err := decodeBody(nil, res, &payload, listEntryHandlers(&payload.EncodingType, func(entry ListEntry) error {
+			return nil
+		}))
+
+		var respErr *ResponseError
+		require.ErrorAs(t, err, &respErr)
+		require.Equal(t, "InternalError", respErr.Payload.Code)
+		require.Equal(t, "42", respErr.RequestID)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		var payload types.ListBucketResultV2
+		err := decodeBody(nil, newResponse(body[:len(body)/2]), &payload, listEntryHandlers(&payload.EncodingType, func(entry ListEntry) error {
+			return nil
+		}))
+		require.Error(t, err)
+	})
+}
+
+func TestListObjectsV2Stream(t *testing.T) {
+	handler := NewSimpleXMLResponseHandler(t, &types.ListBucketResultV2{
+		Name:         aws.String("myBucket"),
+		Prefix:       aws.String("a+b%2F"),
+		EncodingType: aws.String(EncodingTypeURL),
+		KeyCount:     2,
+		Contents:     objects("a+b%2Fc", "a+b%2Fd"),
+		CommonPrefixes: []types.CommonPrefix{
+			{Prefix: aws.String("a+b%2Fe%2F")},
+		},
+	})
+
+	ts, ourClient, _ := NewServer(t, handler)
+	defer ts.Close()
+
+	var keys []string
+	output, err := ourClient.ListObjectsV2Stream(
+		context.Background(),
+		&ListObjectsV2Input{Bucket: "myBucket", EncodingType: aws.String(EncodingTypeURL)},
+		func(entry ListEntry) error {
+			keys = append(keys, entry.Key())
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a b/c", "a b/d", "a b/e/"}, keys)
+	require.Equal(t, aws.String("a b/"), output.Payload.Prefix)
+	require.Equal(t, int32(2), output.Payload.KeyCount)
+	require.Empty(t, output.Payload.Contents)
+}
+
+// TestListObjectsV2StreamEncodingTypeOmitted requests URL-encoded keys from
+// a server that ignores it: the keys are only decoded when the response says
+// they are encoded, as in the payload.
+func TestListObjectsV2StreamEncodingTypeOmitted(t *testing.T) {
+	handler := NewSimpleXMLResponseHandler(t, &types.ListBucketResultV2{
+		Name:     aws.String("myBucket"),
+		Prefix:   aws.String("a%20b"),
+		KeyCount: 1,
+		Contents: objects("a%20b/c+d"),
+	})
+
+	ts, ourClient, _ := NewServer(t, handler)
+	defer ts.Close()
+
+	var keys []string
+	output, err := ourClient.ListObjectsV2Stream(
+		context.Background(),
+		&ListObjectsV2Input{Bucket: "myBucket", EncodingType: aws.String(EncodingTypeURL)},
+		func(entry ListEntry) error {
+			keys = append(keys, entry.Key())
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a%20b/c+d"}, keys)
+	require.Equal(t, aws.String("a%20b"), output.Payload.Prefix)
+}
+
+func TestListPartsStream(t *testing.T) {
+	handler := NewSimpleXMLResponseHandler(t, &types.ListPartsResult{
+		UploadID: aws.String("upload"),
+		Parts: []types.Part{
+			{PartNumber: 1, Size: 10},
+			{PartNumber: 2, Size: 5},
+		},
+	})
+
+	ts, ourClient, _ := NewServer(t, handler)
+	defer ts.Close()
+
+	var sizes []int64
+	output, err := ourClient.ListPartsStream(
+		context.Background(),
+		&ListPartsInput{Bucket: "myBucket", Key: "large.bin", UploadID: "upload"},
+		func(part types.Part) error {
+			sizes = append(sizes, part.Size)
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []int64{10, 5}, sizes)
+	require.Equal(t, aws.String("upload"), output.Payload.UploadID)
+	require.Empty(t, output.Payload.Parts)
+}
+
+// BenchmarkDecodeListing compares the decoding of a listing page read in
+// memory at once, as done before the streaming decoder, with the decoding of
+// the payload and the streaming of the entries from the body.
+func BenchmarkDecodeListing(b *testing.B) {
+	for _, count := range []int{1000, 10000, 100000} {
+		body := newListBucketResultV2(count)
+
+		b.Run(fmt.Sprintf("ReadAll/%d", count), func(b *testing.B) {
+			benchmarkDecode(b, count, func() any {
+				raw, err := io.ReadAll(bytes.NewReader(body))
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				var payload types.ListBucketResultV2
+				if err := xml.Unmarshal(raw, &payload); err != nil {
+					b.Fatal(err)
+				}
+
+				return &payload
+			})
+		})
+
+		b.Run(fmt.Sprintf("Payload/%d", count), func(b *testing.B) {
+			benchmarkDecode(b, count, func() any {
+				var payload types.ListBucketResultV2
+				if err := decodeBody(nil, newResponse(body), &payload, nil); err != nil {
+					b.Fatal(err)
+				}
+
+				return &payload
+			})
+		})
+
+		b.Run(fmt.Sprintf("Streaming/%d", count), func(b *testing.B) {
+			var (
+				size         int64
+				encodingType *string
+			)
+			handlers := listEntryHandlers(&encodingType, func(entry ListEntry) error {
+				if entry.Object != nil {
+					size += entry.Object.Size
+				}
+				return nil
+			})
+
+			benchmarkDecode(b, count, func() any {
+				var payload types.ListBucketResultV2
+				if err := decodeBody(nil, newResponse(body), &payload, handlers); err != nil {
+					b.Fatal(err)
+				}
+
+				return &payload
+			})
+		})
+	}
+}
+
+// benchmarkDecode reports the bytes and allocations per listed entry, and the
+// bytes per entry still referenced by the decoded payload: the streamed
+// entries are released as soon as they are handled.
+func benchmarkDecode(b *testing.B, count int, decode func() any) {
+	var before, after runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	retained := decode()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(retained)
+
+	retainedPerEntry := float64(int64(after.HeapAlloc)-int64(before.HeapAlloc)) / float64(count)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	runtime.ReadMemStats(&before)
+	for i := 0; i < b.N; i++ {
+		decode()
+	}
+	runtime.ReadMemStats(&after)
+
+	b.ReportMetric(float64(after.TotalAlloc-before.TotalAlloc)/float64(b.N)/float64(count), "B/entry")
+	b.ReportMetric(float64(after.Mallocs-before.Mallocs)/float64(b.N)/float64(count), "allocs/entry")
+	b.ReportMetric(retainedPerEntry, "retained-B/entry")
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+// CodeError is a well-known S3 error code. It is meant to be used with
+// errors.Is against the errors returned by the Service.
+type CodeError string
+
+func (e CodeError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrAccessDenied          CodeError = "AccessDenied"
+	ErrBucketAlreadyExists   CodeError = "BucketAlreadyExists"
+	ErrBucketNotEmpty        CodeError = "BucketNotEmpty"
+	ErrInvalidAccessKeyID    CodeError = "InvalidAccessKeyId"
+	ErrInvalidRange          CodeError = "InvalidRange"
+	ErrNoSuchBucket          CodeError = "NoSuchBucket"
+	ErrNoSuchKey             CodeError = "NoSuchKey"
+	ErrNoSuchUpload          CodeError = "NoSuchUpload"
+	ErrPreconditionFailed    CodeError = "PreconditionFailed"
+	ErrSignatureDoesNotMatch CodeError = "SignatureDoesNotMatch"
+
+	// Codes synthesized from the status line of responses without a body,
+	// like the ones of HEAD requests.
+	ErrNotFound    CodeError = "NotFound"
+	ErrForbidden   CodeError = "Forbidden"
+	ErrNotModified CodeError = "NotModified"
+)
+
+// ResponseError is returned when S3 answers with a non-successful status.
+type ResponseError struct {
+	StatusCode int
+	RequestID  string
+	HostID     string
+
+	// Payload is the decoded <Error> document. For responses without a body,
+	// only Payload.Code is filled, from the HTTP status.
+	Payload types.Error
+
+	// VersionID and DeleteMarker are set when the key, or the requested
+	// version, is a delete marker: S3 answers it with a 404, or with a 405
+	// when the version is requested.
+	VersionID    *string
+	DeleteMarker bool
+
+	HTTPRequest  *http.Request
+	HTTPResponse *http.Response
+}
+
+func (e *ResponseError) Error() string {
+	msg := e.Payload.Code
+	if e.Payload.Message != "" {
+		msg += ": " + e.Payload.Message
+	}
+
+	return fmt.Sprintf(
+		"s3: %s (status code: %d, request id: %s, host id: %s)",
+		msg,
+		e.StatusCode,
+		e.RequestID,
+		e.HostID,
+	)
+}
+
+func (e *ResponseError) Code() string {
+	return e.Payload.Code
+}
+
+func (e *ResponseError) Is(target error) bool {
+	code, ok := target.(CodeError)
+	return ok && string(code) == e.Payload.Code
+}
+
+func isSuccessful(res *http.Response) bool {
+	return res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices
+}
+
+// newResponseError consumes and closes the body of a failed response.
+func newResponseError(req *http.Request, res *http.Response) error {
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("cannot read error response body: %w", err)
+	}
+
+	return decodeResponseError(req, res, body)
+}
+
+func decodeResponseError(req *http.Request, res *http.Response, body []byte) *ResponseError {
+	var payload types.Error
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := xml.Unmarshal(body, &payload); err != nil {
+			payload = types.Error{}
+		}
+	}
+
+	return newResponseErrorFromPayload(req, res, payload)
+}
+
+// newResponseErrorFromPayload completes the decoded <Error> document with the
+// response headers.
+func newResponseErrorFromPayload(req *http.Request, res *http.Response, payload types.Error) *ResponseError {
+	respErr := &ResponseError{
+		StatusCode:   res.StatusCode,
+		RequestID:    res.Header.Get("X-Amz-Request-Id"),
+		HostID:       res.Header.Get("X-Amz-Id-2"),
+		Payload:      payload,
+		VersionID:    getStringHeader(res.Header, "X-Amz-Version-Id"),
+		DeleteMarker: getBoolHeader(res.Header, "X-Amz-Delete-Marker"),
+		HTTPRequest:  req,
+		HTTPResponse: res,
+	}
+
+	if respErr.Payload.Code == "" {
+		respErr.Payload.Code = strings.ReplaceAll(http.StatusText(res.StatusCode), " ", "")
+	}
+
+	if respErr.RequestID == "" {
+		respErr.RequestID = respErr.Payload.RequestID
+	}
+
+	if respErr.HostID == "" {
+		respErr.HostID = respErr.Payload.HostID
+	}
+
+	return respErr
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/lvjp/raw-s3-sdk-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func NewErrorResponseHandler(t *testing.T, statusCode int, payload *types.Error) http.HandlerFunc {
+	var raw []byte
+	if payload != nil {
+		var err error
+		raw, err = xml.Marshal(payload)
+		require.NoError(t, err, "Marshal payload")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers := w.Header()
+		headers.Set("X-Amz-Request-Id", "4442587FB7D0A2F9")
+		headers.Set("X-Amz-Id-2", "ZGVhZGJlZWY=")
+		if raw != nil {
+			headers.Set("Content-Type", "application/xml")
+		}
+
+		w.WriteHeader(statusCode)
+		if r.Method != http.MethodHead && raw != nil {
+			_, err := w.Write(raw)
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestErrorResponse(t *testing.T) {
+	expected := types.Error{
+		Code:      "AccessDenied",
+		Message:   "Access Denied",
+		Resource:  "/myBucket/secret.txt",
+		RequestID: "4442587FB7D0A2F9",
+	}
+
+	ts, ourClient, awsClient := NewServer(t, NewErrorResponseHandler(t, http.StatusForbidden, &expected))
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "secret.txt"
+
+	t.Run("our", func(t *testing.T) {
+		_, err := ourClient.GetObject(context.Background(), &GetObjectInput{Bucket: bucket, Key: key})
+		require.Error(t, err)
+
+		var respErr *ResponseError
+		require.ErrorAs(t, err, &respErr)
+		require.Equal(t, expected, respErr.Payload)
+		require.Equal(t, http.StatusForbidden, respErr.StatusCode)
+		require.Equal(t, "4442587FB7D0A2F9", respErr.RequestID)
+		require.Equal(t, "ZGVhZGJlZWY=", respErr.HostID)
+
+		require.ErrorIs(t, err, ErrAccessDenied)
+		require.NotErrorIs(t, err, ErrNoSuchKey)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		_, err := awsClient.GetObject(context.Background(), &s3.GetObjectInput{Bucket: &bucket, Key: &key})
+		require.Error(t, err)
+
+		var apiErr smithy.APIError
+		require.ErrorAs(t, err, &apiErr)
+
+		awsExpected := expected.ToAWS(t)
+		require.Equal(t, awsExpected.ErrorCode(), apiErr.ErrorCode())
+		require.Equal(t, awsExpected.ErrorMessage(), apiErr.ErrorMessage())
+	})
+}
+
+func TestErrorResponseWithoutBody(t *testing.T) {
+	ts, ourClient, awsClient := NewServer(t, NewErrorResponseHandler(t, http.StatusNotFound, nil))
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "missing.txt"
+
+	t.Run("our", func(t *testing.T) {
+		_, err := ourClient.HeadObject(context.Background(), &HeadObjectInput{Bucket: bucket, Key: key})
+
+		var respErr *ResponseError
+		require.ErrorAs(t, err, &respErr)
+		require.Equal(t, "NotFound", respErr.Code())
+		require.Equal(t, "4442587FB7D0A2F9", respErr.RequestID)
+		require.Equal(t, "ZGVhZGJlZWY=", respErr.HostID)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		_, err := awsClient.HeadObject(context.Background(), &s3.HeadObjectInput{Bucket: &bucket, Key: &key})
+
+		var apiErr smithy.APIError
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, "NotFound", apiErr.ErrorCode())
+	})
+}
+
+func TestErrorResponseSentinels(t *testing.T) {
+	for _, code := range []CodeError{ErrNoSuchBucket, ErrNoSuchKey, ErrSignatureDoesNotMatch} {
+		t.Run(string(code), func(t *testing.T) {
+			handler := NewErrorResponseHandler(t, http.StatusForbidden, &types.Error{Code: string(code)})
+			ts, ourClient, _ := NewServer(t, handler)
+			defer ts.Close()
+
+			_, err := ourClient.ListBuckets(context.Background())
+			require.ErrorIs(t, err, code)
+			require.False(t, errors.Is(err, ErrAccessDenied))
+		})
+	}
+}
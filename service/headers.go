@@ -0,0 +1,145 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const metadataHeaderPrefix = "X-Amz-Meta-"
+
+func setStringHeader(header http.Header, name string, value *string) {
+	if value != nil {
+		header.Set(name, *value)
+	}
+}
+
+func setStringQuery(query url.Values, name string, value *string) {
+	if value != nil {
+		query.Set(name, *value)
+	}
+}
+
+func setInt32Query(query url.Values, name string, value *int32) {
+	if value != nil {
+		query.Set(name, strconv.FormatInt(int64(*value), 10))
+	}
+}
+
+func setTimeHeader(header http.Header, name string, value *time.Time) {
+	if value != nil {
+		header.Set(name, value.UTC().Format(http.TimeFormat))
+	}
+}
+
+func setMetadataHeaders(header http.Header, metadata map[string]string) {
+	for key, value := range metadata {
+		header.Set(metadataHeaderPrefix+key, value)
+	}
+}
+
+func getStringHeader(header http.Header, name string) *string {
+	values, ok := header[http.CanonicalHeaderKey(name)]
+	if !ok || len(values) == 0 {
+		return nil
+	}
+
+	value := values[0]
+	return &value
+}
+
+func getTimeHeader(header http.Header, name string) *time.Time {
+	raw := header.Get(name)
+	if raw == "" {
+		return nil
+	}
+
+	parsed, err := http.ParseTime(raw)
+	if err != nil {
+		return nil
+	}
+
+	return &parsed
+}
+
+func getInt64Header(header http.Header, name string) *int64 {
+	raw := header.Get(name)
+	if raw == "" {
+		return nil
+	}
+
+	parsed, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &parsed
+}
+
+func getInt32Header(header http.Header, name string) *int32 {
+	raw := header.Get(name)
+	if raw == "" {
+		return nil
+	}
+
+	parsed, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil {
+		return nil
+	}
+
+	value := int32(parsed)
+	return &value
+}
+
+func getBoolHeader(header http.Header, name string) bool {
+	parsed, err := strconv.ParseBool(header.Get(name))
+	return err == nil && parsed
+}
+
+func getMetadataHeaders(header http.Header) map[string]string {
+	var metadata map[string]string
+
+	for name, values := range header {
+		if !strings.HasPrefix(name, metadataHeaderPrefix) || len(values) == 0 {
+			continue
+		}
+
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata[strings.ToLower(name[len(metadataHeaderPrefix):])] = values[0]
+	}
+
+	return metadata
+}
+
+// bodyLength mirrors http.NewRequest: it returns the size of the well-known
+// in-memory readers and -1 for everything else.
+func bodyLength(body io.Reader) int64 {
+	switch v := body.(type) {
+	case *bytes.Buffer:
+		return int64(v.Len())
+	case *bytes.Reader:
+		return int64(v.Len())
+	case *strings.Reader:
+		return int64(v.Len())
+	default:
+		return -1
+	}
+}
+
+func toReadCloser(body io.Reader) io.ReadCloser {
+	if body == nil {
+		return nil
+	}
+
+	if rc, ok := body.(io.ReadCloser); ok {
+		return rc
+	}
+
+	return io.NopCloser(body)
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+
+	"github.com/lvjp/raw-s3-sdk-go/types"
+)
+
+// EncodingTypeURL makes S3 URL-encode the keys of a listing, which may then
+// hold any character. The keys are decoded before being returned.
+const EncodingTypeURL = "url"
+
+// ListEntry is an object, or a common prefix when listing with a delimiter.
+type ListEntry struct {
+	Object       *types.Object
+	CommonPrefix *string
+}
+
+// Key is the key of the object, or the common prefix.
+func (e ListEntry) Key() string {
+	switch {
+	case e.Object != nil && e.Object.Key != nil:
+		return *e.Object.Key
+	case e.CommonPrefix != nil:
+		return *e.CommonPrefix
+	default:
+		return ""
+	}
+}
+
+// listEntries merges the objects and the common prefixes of a page in key
+// order, as S3 lists them.
+func listEntries(contents []types.Object, prefixes []types.CommonPrefix) []ListEntry {
+	entries := make([]ListEntry, 0, len(contents)+len(prefixes))
+
+	i, j := 0, 0
+	for i < len(contents) || j < len(prefixes) {
+		if j == len(prefixes) || (i < len(contents) && deref(contents[i].Key) < deref(prefixes[j].Prefix)) {
+			entries = append(entries, ListEntry{Object: &contents[i]})
+			i++
+		} else {
+			entries = append(entries, ListEntry{CommonPrefix: prefixes[j].Prefix})
+			j++
+		}
+	}
+
+	return entries
+}
+
+// objectsOf filters the objects of an entry iterator.
+func objectsOf(all func(yield func(ListEntry, error) bool)) func(yield func(types.Object, error) bool) {
+	return func(yield func(types.Object, error) bool) {
+		all(func(entry ListEntry, err error) bool {
+			if err != nil {
+				return yield(types.Object{}, err)
+			}
+
+			if entry.Object == nil {
+				return true
+			}
+
+			return yield(*entry.Object, nil)
+		})
+	}
+}
+
+// decodeURLEncoded decodes in place the values S3 URL-encodes when the
+// listing is requested with EncodingTypeURL.
+func decodeURLEncoded(encodingType *string, values []*string, contents []types.Object, prefixes []types.CommonPrefix) error {
+	if deref(encodingType) != EncodingTypeURL {
+		return nil
+	}
+
+	for i := range contents {
+		values = append(values, contents[i].Key)
+	}
+
+	for i := range prefixes {
+		values = append(values, prefixes[i].Prefix)
+	}
+
+	return decodeURLValues(values...)
+}
+
+func decodeURLValues(values ...*string) error {
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+
+		decoded, err := url.QueryUnescape(*value)
+		if err != nil {
+			return fmt.Errorf("cannot decode the listed key %q: %w", *value, err)
+		}
+		*value = decoded
+	}
+
+	return nil
+}
+
+// listEntryHandlers stream the objects and common prefixes of a listing to
+// fn. Their keys are decoded when the EncodingType of the payload being
+// decoded, which S3 sends before the entries, is EncodingTypeURL: the same
+// rule as for the keys kept in the payload.
+func listEntryHandlers(encodingType **string, fn func(ListEntry) error) elementHandlers {
+	return elementHandlers{
+		"Contents": func(d *xml.Decoder, start *xml.StartElement) error {
+			var object types.Object
+			if err := d.DecodeElement(&object, start); err != nil {
+				return err
+			}
+
+			if deref(*encodingType) == EncodingTypeURL {
+				if err := decodeURLValues(object.Key); err != nil {
+					return err
+				}
+			}
+
+			return fn(ListEntry{Object: &object})
+		},
+		"CommonPrefixes": func(d *xml.Decoder, start *xml.StartElement) error {
+			var prefix types.CommonPrefix
+			if err := d.DecodeElement(&prefix, start); err != nil {
+				return err
+			}
+
+			if deref(*encodingType) == EncodingTypeURL {
+				if err := decodeURLValues(prefix.Prefix); err != nil {
+					return err
+				}
+			}
+
+			return fn(ListEntry{CommonPrefix: prefix.Prefix})
+		},
+	}
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
@@ -0,0 +1,42 @@
+package service
+
+import "github.com/lvjp/raw-s3-sdk-go/config"
+
+// Option overrides the Service configuration for a single call.
+type Option func(*config.Config)
+
+// WithSignatureType signs the call with another signature type than the one
+// of the Service.
+func WithSignatureType(signatureType config.SignatureType) Option {
+	return func(c *config.Config) {
+		c.SignatureType = signatureType
+	}
+}
+
+// WithAnonymous sends the call unsigned, for example to read a public bucket
+// from a Service configured with credentials.
+func WithAnonymous() Option {
+	return WithSignatureType(config.SignatureTypeAnonymous)
+}
+
+// WithPayloadSigning overrides how the Signature Version 4 covers the body of
+// the call.
+func WithPayloadSigning(payloadSigning config.PayloadSigning) Option {
+	return func(c *config.Config) {
+		c.PayloadSigning = payloadSigning
+	}
+}
+
+// withOptions returns the Service to use for a call with the given options.
+func (s *Service) withOptions(optFns []Option) *Service {
+	if len(optFns) == 0 {
+		return s
+	}
+
+	c := s.config
+	for _, fn := range optFns {
+		fn(&c)
+	}
+
+	return &Service{config: c}
+}
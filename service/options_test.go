@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAnonymous(t *testing.T) {
+	var header http.Header
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts, ourClient, _ := NewServer(t, handler)
+	defer ts.Close()
+
+	input := &HeadObjectInput{Bucket: "public-dataset", Key: "README"}
+
+	t.Run("signed", func(t *testing.T) {
+		_, err := ourClient.HeadObject(context.Background(), input)
+		require.NoError(t, err)
+		require.NotEmpty(t, header.Get("Authorization"))
+		require.NotEmpty(t, header.Get("X-Amz-Date"))
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		_, err := ourClient.HeadObject(context.Background(), input, WithAnonymous())
+		require.NoError(t, err)
+		require.Empty(t, header.Get("Authorization"))
+		require.Empty(t, header.Get("X-Amz-Date"))
+		require.Empty(t, header.Get("X-Amz-Content-Sha256"))
+	})
+
+	t.Run("signature type", func(t *testing.T) {
+		_, err := ourClient.HeadObject(context.Background(), input, WithSignatureType(config.SignatureTypeV2Header))
+		require.NoError(t, err)
+		require.Regexp(t, "^AWS DUMMYAIOSFODNN7EXAMPLE:", header.Get("Authorization"))
+	})
+}
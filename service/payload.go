@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/lvjp/raw-s3-sdk-go/signing/utils"
+	signv4 "github.com/lvjp/raw-s3-sdk-go/signing/v4"
+)
+
+// UnsignedPayloadThreshold is the body size from which PayloadSigningAuto
+// stops hashing the payload of requests sent over TLS.
+const UnsignedPayloadThreshold = 16 * 1024 * 1024
+
+const contentSHA256Header = "X-Amz-Content-Sha256"
+
+// preparePayload sets the payload hash the V4 signer will use, so that it
+// never has to read the body in memory. A hash already provided by the
+// caller is kept as-is.
+func (s *Service) preparePayload(req *http.Request, op *operation) error {
+	if s.config.SignatureType != config.SignatureTypeV4 || req.Header.Get(contentSHA256Header) != "" {
+		return nil
+	}
+
+	if op.body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	// Measure seekable bodies first: without a Content-Length, net/http
+	// falls back to a chunked transfer encoding S3 does not accept.
+	if seeker, ok := op.body.(io.ReadSeeker); ok && req.ContentLength < 0 {
+		n, err := remainingLength(seeker)
+		if err != nil {
+			return err
+		}
+		req.ContentLength = n
+	}
+
+	switch s.config.PayloadSigning {
+	case config.PayloadSigningAuto:
+		return s.autoPayload(req, op.body)
+	case config.PayloadSigningHash:
+		return hashedPayload(req, op.body)
+	case config.PayloadSigningUnsigned:
+		req.Header.Set(contentSHA256Header, signv4.UnsignedPayload)
+	case config.PayloadSigningStreaming:
+		return streamingPayload(req)
+	}
+
+	return nil
+}
+
+// autoPayload leaves the payload unsigned when hashing it would need an
+// extra pass over a large body sent over TLS, or when its length is unknown.
+func (s *Service) autoPayload(req *http.Request, body io.Reader) error {
+	unknownLength := req.ContentLength < 0 && bodyLength(body) < 0
+	if unknownLength || (s.config.Endpoint.WithSSL && (req.ContentLength < 0 || req.ContentLength > UnsignedPayloadThreshold)) {
+		req.Header.Set(contentSHA256Header, signv4.UnsignedPayload)
+		return nil
+	}
+
+	return hashedPayload(req, body)
+}
+
+// hashedPayload includes the payload hash in the signature, streaming the
+// bodies that cannot be read twice.
+func hashedPayload(req *http.Request, body io.Reader) error {
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		return hashPayload(req, seeker)
+	}
+
+	if bodyLength(body) >= 0 {
+		// Already in memory, the signer hashes it directly.
+		return nil
+	}
+
+	if req.ContentLength < 0 {
+		return errors.New("cannot sign the payload of a non-seekable body of unknown length")
+	}
+
+	req.Header.Set(contentSHA256Header, signv4.StreamingPayload)
+	return nil
+}
+
+// streamingPayload sends the body as signed aws-chunked chunks, which
+// requires its length to be known upfront.
+func streamingPayload(req *http.Request) error {
+	if req.ContentLength < 0 {
+		return errors.New("cannot stream a body of unknown length")
+	}
+
+	req.Header.Set(contentSHA256Header, signv4.StreamingPayload)
+	return nil
+}
+
+// hashPayload hashes a seekable body in a separate pass.
+func hashPayload(req *http.Request, body io.ReadSeeker) error {
+	hash, _, err := utils.HexSha256ReadSeeker(body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(contentSHA256Header, hash)
+	return nil
+}
+
+// remainingLength returns the number of bytes left to read from body,
+// leaving its offset unchanged.
+func remainingLength(body io.Seeker) (int64, error) {
+	start, err := body.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	end, err := body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := body.Seek(start, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	return end - start, nil
+}
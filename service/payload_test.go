@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreparePayload(t *testing.T) {
+	const content = "some payload"
+
+	sum := sha256.Sum256([]byte(content))
+	contentHash := hex.EncodeToString(sum[:])
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "payload"))
+	require.NoError(t, err)
+	defer file.Close()
+	_, err = io.WriteString(file, content)
+	require.NoError(t, err)
+
+	stream := func() io.Reader { return io.MultiReader(strings.NewReader(content)) }
+
+	testCases := []struct {
+		name           string
+		payloadSigning config.PayloadSigning
+		withSSL        bool
+		body           func() io.Reader
+		contentLength  int64
+		header         http.Header
+		expected       string
+		expectedError  bool
+	}{
+		{name: "AutoSeekable", body: func() io.Reader { return strings.NewReader(content) }, contentLength: -1, expected: contentHash},
+		{name: "AutoFile", body: func() io.Reader { _, _ = file.Seek(0, io.SeekStart); return file }, contentLength: -1, expected: contentHash},
+		{name: "AutoFileOverTLS", withSSL: true, body: func() io.Reader { _, _ = file.Seek(0, io.SeekStart); return file }, contentLength: -1, expected: contentHash},
+		{name: "AutoStream", body: stream, contentLength: int64(len(content)), expected: "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"},
+		{name: "AutoStreamUnknownLength", body: stream, contentLength: -1, expected: "UNSIGNED-PAYLOAD"},
+		{name: "AutoLargeOverTLS", withSSL: true, body: stream, contentLength: UnsignedPayloadThreshold + 1, expected: "UNSIGNED-PAYLOAD"},
+		{name: "AutoSmallOverTLS", withSSL: true, body: func() io.Reader { return strings.NewReader(content) }, contentLength: int64(len(content)), expected: contentHash},
+		{name: "HashStream", payloadSigning: config.PayloadSigningHash, withSSL: true, body: stream, contentLength: UnsignedPayloadThreshold + 1, expected: "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"},
+		{name: "HashStreamUnknownLength", payloadSigning: config.PayloadSigningHash, body: stream, contentLength: -1, expectedError: true},
+		{name: "Unsigned", payloadSigning: config.PayloadSigningUnsigned, body: func() io.Reader { return strings.NewReader(content) }, contentLength: -1, expected: "UNSIGNED-PAYLOAD"},
+		{name: "Streaming", payloadSigning: config.PayloadSigningStreaming, body: func() io.Reader { return strings.NewReader(content) }, contentLength: int64(len(content)), expected: "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"},
+		{name: "StreamingUnknownLength", payloadSigning: config.PayloadSigningStreaming, body: stream, contentLength: -1, expectedError: true},
+		{name: "Precomputed", body: stream, contentLength: -1, header: http.Header{contentSHA256Header: {"precomputed"}}, expected: "precomputed"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := New(config.Config{
+				Endpoint:       config.Endpoint{Host: "s3.example.com", Port: 443, WithSSL: tc.withSSL},
+				SignatureType:  config.SignatureTypeV4,
+				PayloadSigning: tc.payloadSigning,
+			})
+
+			op := &operation{
+				method:        http.MethodPut,
+				header:        tc.header,
+				body:          tc.body(),
+				contentLength: tc.contentLength,
+			}
+			req := s.newRequest(context.Background(), op)
+
+			err := s.preparePayload(req, op)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, req.Header.Get(contentSHA256Header))
+			if _, ok := op.body.(io.Seeker); ok {
+				require.Equal(t, int64(len(content)), req.ContentLength, "seekable bodies must have a known length")
+			}
+
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.Equal(t, content, string(body), "the body must be rewound after hashing")
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/lvjp/raw-s3-sdk-go/signing"
+	signv4 "github.com/lvjp/raw-s3-sdk-go/signing/v4"
+)
+
+// PresignedRequest is a request which can be sent without the credentials
+// until it expires.
+type PresignedRequest struct {
+	Method string
+	URL    string
+
+	// Header must be sent along with the URL as it is part of the signature.
+	Header http.Header
+}
+
+// Presign builds a presigned URL for the given bucket and key. The headers
+// are signed, so the party using the URL has to send them unchanged.
+func (s *Service) Presign(ctx context.Context, method string, bucket, key *string, queryString url.Values, header http.Header, expires time.Duration, optFns ...Option) (*PresignedRequest, error) {
+	svc := s.withOptions(optFns)
+
+	req := svc.newRequest(ctx, &operation{
+		method: method,
+		bucket: bucket,
+		key:    key,
+		query:  queryString,
+		header: header.Clone(),
+	})
+
+	presigner, err := signing.NewPresigner(svc.config.SignatureType)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := svc.retrieveCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := presigner(req, credentials, svc.config.Region, expires); err != nil {
+		return nil, err
+	}
+
+	signedHeader := header.Clone()
+	if signedHeader == nil {
+		signedHeader = http.Header{}
+	}
+
+	return &PresignedRequest{
+		Method: method,
+		URL:    req.URL.String(),
+		Header: signedHeader,
+	}, nil
+}
+
+// PresignedPost is a browser-based POST upload, which can be sent without the
+// credentials until its policy expires.
+type PresignedPost struct {
+	URL string
+
+	// Fields must be embedded in the form, before the file field.
+	Fields map[string]string
+}
+
+// PresignPost signs the POST policy of an upload to the bucket. The policy
+// should hold the bucket condition, as S3 enforces it.
+func (s *Service) PresignPost(ctx context.Context, bucket string, policy *signv4.PostPolicy, optFns ...Option) (*PresignedPost, error) {
+	svc := s.withOptions(optFns)
+
+	if svc.config.SignatureType != config.SignatureTypeV4 {
+		return nil, errors.New("POST policies require the Signature Version 4")
+	}
+
+	credentials, err := svc.retrieveCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := signv4.SignPostPolicy(policy, credentials, svc.config.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresignedPost{
+		URL:    svc.newURL(&bucket, nil, nil).String(),
+		Fields: fields,
+	}, nil
+}
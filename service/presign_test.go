@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresign(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "/myBucket/upload.bin", r.URL.Path)
+		require.Empty(t, r.Header.Get("Authorization"))
+
+		query := r.URL.Query()
+		require.Equal(t, "AWS4-HMAC-SHA256", query.Get("X-Amz-Algorithm"))
+		require.Equal(t, "3600", query.Get("X-Amz-Expires"))
+		require.Equal(t, "content-type;host", query.Get("X-Amz-SignedHeaders"))
+		require.Len(t, query.Get("X-Amz-Signature"), 64)
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ts, ourClient, _ := NewServer(t, handler)
+	defer ts.Close()
+
+	bucket := "myBucket"
+	key := "upload.bin"
+
+	presigned, err := ourClient.Presign(
+		context.Background(),
+		http.MethodPut,
+		&bucket,
+		&key,
+		nil,
+		http.Header{"Content-Type": {"application/octet-stream"}},
+		time.Hour,
+	)
+	require.NoError(t, err)
+	require.Equal(t, http.MethodPut, presigned.Method)
+	require.Equal(t, http.Header{"Content-Type": {"application/octet-stream"}}, presigned.Header)
+
+	req, err := http.NewRequestWithContext(context.Background(), presigned.Method, presigned.URL, http.NoBody)
+	require.NoError(t, err)
+	req.Header = presigned.Header
+
+	res, err := ts.Client().Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
@@ -2,19 +2,16 @@ package service
 
 import (
 	"context"
-	"encoding/xml"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"strconv"
 
 	"github.com/lvjp/raw-s3-sdk-go/config"
 	"github.com/lvjp/raw-s3-sdk-go/signing"
+	"github.com/lvjp/raw-s3-sdk-go/signing/utils"
 )
 
-const standardHTTPPort = 80
-const standardHTTPSPort = 443
-
 type Service struct {
 	config config.Config
 }
@@ -29,15 +26,51 @@ func New(config config.Config) *Service {
 	}
 }
 
-func (s *Service) Do(ctx context.Context, method string, bucket, key *string, queryString url.Values, body io.ReadCloser) (*http.Request, *http.Response, error) {
-	req := s.newRequest(ctx, method, bucket, key, queryString, body)
+// Do sends a raw request. Unlike the typed operations, the response is
+// returned as-is whatever its status code.
+func (s *Service) Do(ctx context.Context, method string, bucket, key *string, queryString url.Values, body io.ReadCloser, optFns ...Option) (*http.Request, *http.Response, error) {
+	return s.withOptions(optFns).send(ctx, &operation{
+		method: method,
+		bucket: bucket,
+		key:    key,
+		query:  queryString,
+		body:   body,
+
+		contentLength: -1,
+	})
+}
+
+// operation describes a single S3 call before it is turned into an HTTP request.
+type operation struct {
+	method string
+	bucket *string
+	key    *string
+	query  url.Values
+	header http.Header
+	body   io.Reader
+
+	// contentLength is the size of body, or -1 when it is unknown.
+	contentLength int64
+}
+
+func (s *Service) send(ctx context.Context, op *operation) (*http.Request, *http.Response, error) {
+	req := s.newRequest(ctx, op)
+
+	if err := s.preparePayload(req, op); err != nil {
+		return nil, nil, err
+	}
 
 	signer, err := signing.NewSigner(s.config.SignatureType)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	err = signer(req, s.config.Credentials, s.config.Region)
+	credentials, err := s.retrieveCredentials(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = signer(req, credentials, s.config.Region)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -47,53 +80,81 @@ func (s *Service) Do(ctx context.Context, method string, bucket, key *string, qu
 	return req, resp, err
 }
 
-func (s *Service) newRequest(ctx context.Context, method string, bucket, key *string, queryString url.Values, body io.ReadCloser) *http.Request {
-	url := s.newURL(bucket, key, queryString)
+// retrieveCredentials returns the credentials to sign a request with. The
+// provider is not queried for anonymous requests.
+func (s *Service) retrieveCredentials(ctx context.Context) (config.Credentials, error) {
+	if s.config.SignatureType == config.SignatureTypeAnonymous || s.config.Credentials == nil {
+		return config.Credentials{}, nil
+	}
+
+	credentials, err := s.config.Credentials.Retrieve(ctx)
+	if err != nil {
+		return config.Credentials{}, fmt.Errorf("cannot retrieve credentials: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// call sends the operation and turns non-successful responses into a
+// *ResponseError.
+func (s *Service) call(ctx context.Context, op *operation) (*http.Request, *http.Response, error) {
+	req, resp, err := s.send(ctx, op)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !isSuccessful(resp) {
+		return nil, nil, newResponseError(req, resp)
+	}
+
+	return req, resp, nil
+}
+
+func (s *Service) newRequest(ctx context.Context, op *operation) *http.Request {
+	url := s.newURL(op.bucket, op.key, op.query)
+
+	header := http.Header{
+		"User-Agent": []string{"raw-s3-sdk-go"},
+	}
+	for name, values := range op.header {
+		header[name] = values
+	}
 
 	req := &http.Request{
-		Method:     method,
+		Method:     op.method,
 		URL:        url,
 		Proto:      "HTTP/1.1",
 		ProtoMajor: 1,
 		ProtoMinor: 1,
-		Header: http.Header{
-			"User-Agent": []string{"raw-s3-sdk-go"},
-		},
-		Body: body,
-		Host: url.Host,
+		Header:     header,
+		Body:       toReadCloser(op.body),
+		Host:       url.Host,
 	}
 
-	return req.WithContext(ctx)
-}
-
-func (s *Service) newURL(bucket *string, key *string, queryString url.Values) *url.URL {
-	e := &s.config.Endpoint
-
-	url := &url.URL{
-		Host: e.Host,
+	if req.Body != nil && req.Body != http.NoBody {
+		req.ContentLength = op.contentLength
 	}
 
-	if e.WithVirtualHost && bucket != nil {
-		url.Host = *bucket + url.Host
+	e := &s.config.Endpoint
+	if op.bucket != nil && useVirtualHost(e.WithVirtualHost, e.WithSSL, *op.bucket) {
+		ctx = utils.WithVirtualHostBucket(ctx, *op.bucket)
 	}
 
-	if e.WithSSL {
-		url.Scheme = "https"
-		if e.Port != standardHTTPSPort {
-			url.Host += ":" + strconv.Itoa(e.Port)
-		}
-	} else {
-		url.Scheme = "http"
-		if e.Port != standardHTTPPort {
-			url.Host += ":" + strconv.Itoa(e.Port)
-		}
-	}
+	return req.WithContext(ctx)
+}
 
-	return url
+// doCall is call followed by the decoding of the XML response body. Some
+// operations, like CompleteMultipartUpload, can fail after S3 has sent a 200
+// status: the body is then an <Error> document, returned as a *ResponseError.
+func (s *Service) doCall(ctx context.Context, op *operation, respBody any) (*http.Request, *http.Response, error) {
+	return s.doStreamingCall(ctx, op, respBody, nil)
 }
 
-func (s *Service) doCall(ctx context.Context, respBody any) (*http.Request, *http.Response, error) {
-	req, resp, err := s.Do(ctx, http.MethodGet, nil, nil, nil, nil)
+// doStreamingCall is doCall passing the children of the root element named in
+// handlers to their handler as they are parsed, instead of decoding them into
+// respBody.
+func (s *Service) doStreamingCall(ctx context.Context, op *operation, respBody any, handlers elementHandlers) (*http.Request, *http.Response, error) {
+	req, resp, err := s.call(ctx, op)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -101,12 +162,7 @@ func (s *Service) doCall(ctx context.Context, respBody any) (*http.Request, *htt
 	defer resp.Body.Close()
 
 	if respBody != nil {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, nil, err
-		}
-
-		if err := xml.Unmarshal(body, respBody); err != nil {
+		if err := decodeBody(req, resp, respBody, handlers); err != nil {
 			return nil, nil, err
 		}
 	}
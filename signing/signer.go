@@ -3,8 +3,10 @@ package signing
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/lvjp/raw-s3-sdk-go/config"
+	signv2 "github.com/lvjp/raw-s3-sdk-go/signing/v2"
 	signv4 "github.com/lvjp/raw-s3-sdk-go/signing/v4"
 )
 
@@ -12,9 +14,58 @@ type Signer func(r *http.Request, credentials config.Credentials, region string)
 
 func NewSigner(signatureType config.SignatureType) (Signer, error) {
 	switch signatureType {
+	case config.SignatureTypeAnonymous:
+		return Anonymous, nil
+	case config.SignatureTypeV2:
+		return signv2.SignQuery, nil
+	case config.SignatureTypeV2Header:
+		return signv2.Sign, nil
 	case config.SignatureTypeV4:
 		return signv4.Sign, nil
 	default:
 		return nil, fmt.Errorf("unsupported signature type: %v", signatureType)
 	}
 }
+
+// Presigner adds query string authentication to a request, valid for the
+// given duration.
+type Presigner func(r *http.Request, credentials config.Credentials, region string, expires time.Duration) error
+
+func NewPresigner(signatureType config.SignatureType) (Presigner, error) {
+	switch signatureType {
+	case config.SignatureTypeAnonymous:
+		return presignAnonymous, nil
+	case config.SignatureTypeV2, config.SignatureTypeV2Header:
+		return presignV2, nil
+	case config.SignatureTypeV4:
+		return signv4.Presign, nil
+	default:
+		return nil, fmt.Errorf("unsupported signature type: %v", signatureType)
+	}
+}
+
+func presignV2(r *http.Request, credentials config.Credentials, region string, expires time.Duration) error {
+	return signv2.Presign(r, credentials, time.Now().Add(expires))
+}
+
+func presignAnonymous(r *http.Request, credentials config.Credentials, region string, expires time.Duration) error {
+	return Anonymous(r, credentials, region)
+}
+
+// signingHeaders are the headers only meaningful to authenticated requests.
+var signingHeaders = []string{
+	"Authorization",
+	"X-Amz-Content-Sha256",
+	"X-Amz-Date",
+	"X-Amz-Security-Token",
+}
+
+// Anonymous leaves the request unsigned so it can reach public resources.
+// Signing headers set by the caller are removed.
+func Anonymous(r *http.Request, credentials config.Credentials, region string) error {
+	for _, name := range signingHeaders {
+		r.Header.Del(name)
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package utils
+
+import "context"
+
+type virtualHostBucketKey struct{}
+
+// WithVirtualHostBucket records that the request addresses bucket through its
+// host name. Signers which canonicalize the bucket, like Signature Version 2,
+// cannot recover it from the URL alone.
+func WithVirtualHostBucket(ctx context.Context, bucket string) context.Context {
+	return context.WithValue(ctx, virtualHostBucketKey{}, bucket)
+}
+
+func VirtualHostBucket(ctx context.Context) (string, bool) {
+	bucket, ok := ctx.Value(virtualHostBucketKey{}).(string)
+	return bucket, ok
+}
@@ -2,8 +2,11 @@ package utils
 
 import (
 	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // required by the Signature Version 2
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"io"
 )
 
 func HMacSha256(key []byte, content string) []byte {
@@ -16,3 +19,30 @@ func HexSha256(content string) string {
 	sum := sha256.Sum256([]byte(content))
 	return hex.EncodeToString(sum[:])
 }
+
+func Base64HMacSha1(key []byte, content string) string {
+	mac := hmac.New(sha1.New, key)
+	mac.Write([]byte(content))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// HexSha256ReadSeeker hashes the content from the current position to the end
+// and rewinds it. It returns the hash and the number of bytes hashed.
+func HexSha256ReadSeeker(content io.ReadSeeker) (string, int64, error) {
+	start, err := content.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, err
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(hash, content)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if _, err := content.Seek(start, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), n, nil
+}
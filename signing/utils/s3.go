@@ -1,31 +1,40 @@
 package utils
 
-func URIEncode(input string) string {
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// URIEncode implements the UriEncode function described in the AWS Signature
+// Version 4 documentation: every byte except the unreserved characters is
+// percent-encoded, and '/' is kept as-is unless encodeSlash is set.
+func URIEncode(input string, encodeSlash bool) string {
 	const upperhex = "0123456789ABCDEF"
 
 	hexCount := 0
 
 	for i := 0; i < len(input); i++ {
-		if shouldEscape(input[i]) {
+		if shouldEscape(input[i], encodeSlash) {
 			hexCount++
 		}
 	}
 
+	if hexCount == 0 {
+		return input
+	}
+
 	output := make([]byte, len(input)+2*hexCount)
 
 	j := 0
 	for i := 0; i < len(input); i++ {
-		switch c := input[i]; {
-		case c == ' ':
-			output[j] = '+'
-			j++
-		case shouldEscape(c):
+		if c := input[i]; shouldEscape(c, encodeSlash) {
 			output[j] = '%'
 			output[j+1] = upperhex[c>>4]
 			output[j+2] = upperhex[c&15]
 			j += 3
-		default:
-			output[j] = input[i]
+		} else {
+			output[j] = c
 			j++
 		}
 	}
@@ -33,7 +42,42 @@ func URIEncode(input string) string {
 	return string(output)
 }
 
-func shouldEscape(c byte) bool {
+// CanonicalQueryString encodes the query string the way S3 canonicalizes it:
+// both keys and values are URI-encoded, then sorted by key and, for repeated
+// keys, by value.
+func CanonicalQueryString(query url.Values) string {
+	type pair struct{ key, value string }
+
+	pairs := make([]pair, 0, len(query))
+	for key, values := range query {
+		keyEscaped := URIEncode(key, true)
+		for _, value := range values {
+			pairs = append(pairs, pair{keyEscaped, URIEncode(value, true)})
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+
+		return pairs[i].value < pairs[j].value
+	})
+
+	buf := strings.Builder{}
+	for _, p := range pairs {
+		if buf.Len() > 0 {
+			buf.WriteByte('&')
+		}
+		buf.WriteString(p.key)
+		buf.WriteByte('=')
+		buf.WriteString(p.value)
+	}
+
+	return buf.String()
+}
+
+func shouldEscape(c byte, encodeSlash bool) bool {
 	switch {
 	case 'a' <= c && c <= 'z':
 	case 'A' <= c && c <= 'Z':
@@ -43,6 +87,7 @@ func shouldEscape(c byte) bool {
 	case c == '.':
 	case c == '~':
 	case c == '/':
+		return encodeSlash
 	default:
 		return true
 	}
@@ -0,0 +1,195 @@
+package signing
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/lvjp/raw-s3-sdk-go/signing/utils"
+	"golang.org/x/exp/maps"
+)
+
+// DefaultExpiration is the validity of the query string signatures computed
+// by SignQuery.
+const DefaultExpiration = 15 * time.Minute
+
+// subResources lists the query parameters which are part of the
+// canonicalized resource.
+var subResources = map[string]struct{}{
+	"accelerate":                   {},
+	"acl":                          {},
+	"cors":                         {},
+	"delete":                       {},
+	"encryption":                   {},
+	"lifecycle":                    {},
+	"location":                     {},
+	"logging":                      {},
+	"notification":                 {},
+	"partNumber":                   {},
+	"policy":                       {},
+	"replication":                  {},
+	"requestPayment":               {},
+	"response-cache-control":       {},
+	"response-content-disposition": {},
+	"response-content-encoding":    {},
+	"response-content-language":    {},
+	"response-content-type":        {},
+	"response-expires":             {},
+	"restore":                      {},
+	"tagging":                      {},
+	"torrent":                      {},
+	"uploadId":                     {},
+	"uploads":                      {},
+	"versionId":                    {},
+	"versioning":                   {},
+	"versions":                     {},
+	"website":                      {},
+}
+
+// Sign signs the request with the Authorization header form of the
+// Signature Version 2. The region is not used by this signature version.
+func Sign(r *http.Request, credentials config.Credentials, region string) error {
+	if credentials.SessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	if r.Header.Get("Date") == "" && r.Header.Get("X-Amz-Date") == "" {
+		r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+
+	// The x-amz-date header takes precedence and is signed as an amz header.
+	date := r.Header.Get("Date")
+	if r.Header.Get("X-Amz-Date") != "" {
+		date = ""
+	}
+
+	signature := computeSignature(credentials, computeStringToSign(r, r.Header, date))
+	r.Header.Set("Authorization", "AWS "+credentials.AccessKey+":"+signature)
+
+	return nil
+}
+
+// SignQuery signs the request with the query string form of the Signature
+// Version 2, valid for DefaultExpiration.
+func SignQuery(r *http.Request, credentials config.Credentials, region string) error {
+	return Presign(r, credentials, time.Now().Add(DefaultExpiration))
+}
+
+// Presign adds the AWSAccessKeyId, Expires and Signature query parameters to
+// the request URL.
+func Presign(r *http.Request, credentials config.Credentials, expires time.Time) error {
+	expiresRaw := strconv.FormatInt(expires.Unix(), 10)
+
+	// The security token is sent as a query parameter but signed as if it
+	// was an amz header.
+	header := r.Header
+	query := r.URL.Query()
+	if credentials.SessionToken != "" {
+		header = header.Clone()
+		header.Set("X-Amz-Security-Token", credentials.SessionToken)
+		query.Set("x-amz-security-token", credentials.SessionToken)
+	}
+
+	signature := computeSignature(credentials, computeStringToSign(r, header, expiresRaw))
+
+	query.Set("AWSAccessKeyId", credentials.AccessKey)
+	query.Set("Expires", expiresRaw)
+	query.Set("Signature", signature)
+	r.URL.RawQuery = utils.CanonicalQueryString(query)
+
+	return nil
+}
+
+func computeSignature(credentials config.Credentials, stringToSign string) string {
+	return utils.Base64HMacSha1([]byte(credentials.SecretKey), stringToSign)
+}
+
+// computeStringToSign builds the string to sign. The date is the Date header
+// for the header form, and the Expires value for the query string form.
+func computeStringToSign(r *http.Request, header http.Header, date string) string {
+	buf := strings.Builder{}
+	buf.WriteString(r.Method)
+	buf.WriteByte('\n')
+	buf.WriteString(header.Get("Content-Md5"))
+	buf.WriteByte('\n')
+	buf.WriteString(header.Get("Content-Type"))
+	buf.WriteByte('\n')
+	buf.WriteString(date)
+	buf.WriteByte('\n')
+	buf.WriteString(computeCanonicalizedAmzHeaders(header))
+	buf.WriteString(computeCanonicalizedResource(r))
+
+	return buf.String()
+}
+
+func computeCanonicalizedAmzHeaders(header http.Header) string {
+	headers := make(map[string][]string)
+
+	for key, values := range header {
+		name := strings.ToLower(key)
+		if strings.HasPrefix(name, "x-amz-") {
+			headers[name] = append(headers[name], values...)
+		}
+	}
+
+	keys := maps.Keys(headers)
+	sort.Strings(keys)
+
+	buf := strings.Builder{}
+	for _, key := range keys {
+		values := headers[key]
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+
+		buf.WriteString(key)
+		buf.WriteByte(':')
+		buf.WriteString(strings.Join(values, ","))
+		buf.WriteByte('\n')
+	}
+
+	return buf.String()
+}
+
+func computeCanonicalizedResource(r *http.Request) string {
+	buf := strings.Builder{}
+
+	if bucket, ok := utils.VirtualHostBucket(r.Context()); ok {
+		buf.WriteByte('/')
+		buf.WriteString(bucket)
+	}
+
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	buf.WriteString(path)
+
+	query := r.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if _, ok := subResources[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for i, key := range keys {
+		if i == 0 {
+			buf.WriteByte('?')
+		} else {
+			buf.WriteByte('&')
+		}
+		buf.WriteString(key)
+
+		if value := query.Get(key); value != "" {
+			buf.WriteByte('=')
+			buf.WriteString(value)
+		}
+	}
+
+	return buf.String()
+}
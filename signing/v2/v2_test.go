@@ -0,0 +1,212 @@
+package signing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/lvjp/raw-s3-sdk-go/signing/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var creds = config.Credentials{
+	AccessKey: "AKI" + "AIOSFODNN7EXAMPLE",
+	SecretKey: "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY",
+}
+
+func TestSign(t *testing.T) {
+	for _, tc := range generateTestCases(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Sign(tc.request, creds, "us-east-1")
+
+			require.NoError(t, err)
+			require.Equal(t, "AWS "+creds.AccessKey+":"+tc.expected, tc.request.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestPresign(t *testing.T) {
+	r := newRequest(t, "johnsmith", http.MethodGet, "http://johnsmith.s3.amazonaws.com/photos/puppy.jpg", nil)
+
+	err := Presign(r, creds, time.Unix(1175139620, 0))
+	require.NoError(t, err)
+
+	query := r.URL.Query()
+	require.Equal(t, creds.AccessKey, query.Get("AWSAccessKeyId"))
+	require.Equal(t, "1175139620", query.Get("Expires"))
+	require.Equal(t, "NpgCjnDzrM+WFzoENXmpNDUsSn8=", query.Get("Signature"))
+	require.Empty(t, r.Header.Get("Authorization"))
+}
+
+func newRequest(t *testing.T, virtualHostBucket, method, url string, headers map[string][]string) *http.Request {
+	ctx := context.Background()
+	if virtualHostBucket != "" {
+		ctx = utils.WithVirtualHostBucket(ctx, virtualHostBucket)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, method, url, nil)
+	require.NoError(t, err)
+
+	for key, values := range headers {
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+
+	return r
+}
+
+type testCase struct {
+	name     string
+	request  *http.Request
+	expected string
+}
+
+// generateTestCases returns the examples of the "Signing and authenticating
+// REST requests" page of the Amazon S3 documentation.
+func generateTestCases(t *testing.T) []testCase {
+	return []testCase{
+		{
+			name: "ObjectGET",
+			request: newRequest(
+				t,
+				"johnsmith",
+				http.MethodGet,
+				"http://johnsmith.s3.amazonaws.com/photos/puppy.jpg",
+				map[string][]string{
+					"Date": {"Tue, 27 Mar 2007 19:36:42 +0000"},
+				},
+			),
+			expected: "bWq2s1WEIj+Ydj0vQ697zp+IXMU=",
+		},
+
+		{
+			name: "ObjectPUT",
+			request: newRequest(
+				t,
+				"johnsmith",
+				http.MethodPut,
+				"http://johnsmith.s3.amazonaws.com/photos/puppy.jpg",
+				map[string][]string{
+					"Content-Type":   {"image/jpeg"},
+					"Content-Length": {"94328"},
+					"Date":           {"Tue, 27 Mar 2007 21:15:45 +0000"},
+				},
+			),
+			expected: "MyyxeRY7whkBe+bq8fHCL/2kKUg=",
+		},
+
+		{
+			name: "List",
+			request: newRequest(
+				t,
+				"johnsmith",
+				http.MethodGet,
+				"http://johnsmith.s3.amazonaws.com/?prefix=photos&max-keys=50&marker=puppy",
+				map[string][]string{
+					"User-Agent": {"Mozilla/5.0"},
+					"Date":       {"Tue, 27 Mar 2007 19:42:41 +0000"},
+				},
+			),
+			expected: "htDYFYduRNen8P9ZfE/s9SuKy0U=",
+		},
+
+		{
+			name: "Fetch",
+			request: newRequest(
+				t,
+				"johnsmith",
+				http.MethodGet,
+				"http://johnsmith.s3.amazonaws.com/?acl",
+				map[string][]string{
+					"Date": {"Tue, 27 Mar 2007 19:44:46 +0000"},
+				},
+			),
+			expected: "c2WLPFtWHVgbEmeEG93a4cG37dM=",
+		},
+
+		{
+			name: "Upload",
+			request: newRequest(
+				t,
+				"static.johnsmith.net",
+				http.MethodPut,
+				"http://static.johnsmith.net:8080/db-backup.dat.gz",
+				map[string][]string{
+					"User-Agent":                   {"curl/7.15.5"},
+					"Date":                         {"Tue, 27 Mar 2007 21:06:08 +0000"},
+					"x-amz-acl":                    {"public-read"},
+					"content-type":                 {"application/x-download"},
+					"Content-MD5":                  {"4gJE4saaMU4BqNR0kLY+lw=="},
+					"X-Amz-Meta-ReviewedBy":        {"joe@johnsmith.net", "jane@johnsmith.net"},
+					"X-Amz-Meta-FileChecksum":      {"0x02661779"},
+					"X-Amz-Meta-ChecksumAlgorithm": {"crc32"},
+					"Content-Disposition":          {"attachment; filename=database.dat"},
+					"Content-Encoding":             {"gzip"},
+					"Content-Length":               {"5913339"},
+				},
+			),
+			expected: "ilyl83RwaSoYIEdixDQcA4OnAnc=",
+		},
+
+		{
+			name: "ListAllMyBuckets",
+			request: newRequest(
+				t,
+				"",
+				http.MethodGet,
+				"http://s3.amazonaws.com/",
+				map[string][]string{
+					"Date": {"Wed, 28 Mar 2007 01:29:59 +0000"},
+				},
+			),
+			expected: "qGdzdERIC03wnaRNKh6OqZehG9s=",
+		},
+
+		{
+			name: "UnicodeKeys",
+			request: newRequest(
+				t,
+				"",
+				http.MethodGet,
+				"http://s3.amazonaws.com/dictionary/fran%C3%A7ais/pr%c3%a9f%c3%a8re",
+				map[string][]string{
+					"Date": {"Wed, 28 Mar 2007 01:49:49 +0000"},
+				},
+			),
+			expected: "DNEZGsoieTZ92F3bUfSPQcbGmlM=",
+		},
+	}
+}
+
+func TestSessionToken(t *testing.T) {
+	sessionCreds := creds
+	sessionCreds.SessionToken = "AQoDYXdzEPT//////////wEXAMPLEtc764bNrC9SAPBSM22wDOk4x4HIZ8j4FZTwdQW"
+
+	t.Run("header", func(t *testing.T) {
+		r := newRequest(t, "johnsmith", http.MethodGet, "http://johnsmith.s3.amazonaws.com/photos/puppy.jpg", map[string][]string{
+			"Date": {"Tue, 27 Mar 2007 19:36:42 +0000"},
+		})
+
+		require.NoError(t, Sign(r, sessionCreds, "us-east-1"))
+		require.Equal(t, sessionCreds.SessionToken, r.Header.Get("X-Amz-Security-Token"))
+
+		expected := "GET\n\n\nTue, 27 Mar 2007 19:36:42 +0000\nx-amz-security-token:" + sessionCreds.SessionToken + "\n/johnsmith/photos/puppy.jpg"
+		require.Equal(t, "AWS "+creds.AccessKey+":"+computeSignature(creds, expected), r.Header.Get("Authorization"))
+	})
+
+	t.Run("query", func(t *testing.T) {
+		r := newRequest(t, "johnsmith", http.MethodGet, "http://johnsmith.s3.amazonaws.com/photos/puppy.jpg", nil)
+
+		require.NoError(t, Presign(r, sessionCreds, time.Unix(1175139620, 0)))
+		require.Empty(t, r.Header.Get("X-Amz-Security-Token"))
+
+		query := r.URL.Query()
+		require.Equal(t, sessionCreds.SessionToken, query.Get("x-amz-security-token"))
+
+		expected := "GET\n\n\n1175139620\nx-amz-security-token:" + sessionCreds.SessionToken + "\n/johnsmith/photos/puppy.jpg"
+		require.Equal(t, computeSignature(creds, expected), query.Get("Signature"))
+	})
+}
@@ -0,0 +1,93 @@
+package signing
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+)
+
+// signingKeyCacheSize bounds the number of derived signing keys kept in memory.
+// A key is valid for a day, so it only needs to hold the keys of the
+// credentials, regions and services in use at the same time.
+const signingKeyCacheSize = 256
+
+// keyCache is shared by every signer.
+var keyCache = newSigningKeyCache(signingKeyCacheSize)
+
+// signingKeyID identifies a derived key by the credentials it comes from. The
+// secret key is only kept as a hash, so that a rotated secret sharing its
+// access key gets its own entry and the cache never holds plaintext secrets.
+type signingKeyID struct {
+	accessKey  string
+	secretHash [sha256.Size]byte
+	date       string
+	region     string
+	service    string
+}
+
+func newSigningKeyID(accessKey, secretKey, date, region, service string) signingKeyID {
+	return signingKeyID{
+		accessKey:  accessKey,
+		secretHash: sha256.Sum256([]byte(secretKey)),
+		date:       date,
+		region:     region,
+		service:    service,
+	}
+}
+
+type signingKeyEntry struct {
+	id  signingKeyID
+	key []byte
+}
+
+// signingKeyCache is a concurrency safe LRU cache of derived signing keys.
+type signingKeyCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[signingKeyID]*list.Element
+}
+
+func newSigningKeyCache(size int) *signingKeyCache {
+	return &signingKeyCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[signingKeyID]*list.Element, size),
+	}
+}
+
+// get returns the cached key. The returned slice must not be modified.
+func (c *signingKeyCache) get(id signingKeyID) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	return elem.Value.(*signingKeyEntry).key, true
+}
+
+// put stores the key, evicting the least recently used one when full.
+func (c *signingKeyCache) put(id signingKeyID, key []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &signingKeyEntry{id: id, key: key}
+	if elem, ok := c.entries[id]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[id] = c.order.PushFront(entry)
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*signingKeyEntry).id)
+	}
+}
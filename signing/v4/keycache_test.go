@@ -0,0 +1,140 @@
+package signing
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigningKeyCache(t *testing.T) {
+	cache := newSigningKeyCache(2)
+	id := func(secretKey string, n int) signingKeyID {
+		return newSigningKeyID("AKID", secretKey, "2015083"+strconv.Itoa(n), "us-east-1", "s3")
+	}
+
+	_, ok := cache.get(id("secret", 0))
+	require.False(t, ok)
+
+	cache.put(id("secret", 0), []byte("key0"))
+	key, ok := cache.get(id("secret", 0))
+	require.True(t, ok)
+	require.Equal(t, []byte("key0"), key)
+
+	// A rotated secret sharing the access key has its own entry.
+	_, ok = cache.get(id("rotated", 0))
+	require.False(t, ok)
+	cache.put(id("rotated", 0), []byte("rotated0"))
+	key, ok = cache.get(id("secret", 0))
+	require.True(t, ok)
+	require.Equal(t, []byte("key0"), key)
+	key, ok = cache.get(id("rotated", 0))
+	require.True(t, ok)
+	require.Equal(t, []byte("rotated0"), key)
+
+	// The rotated key was used last, so the other one is evicted.
+	cache.put(id("secret", 1), []byte("key1"))
+	_, ok = cache.get(id("secret", 0))
+	require.False(t, ok)
+	_, ok = cache.get(id("rotated", 0))
+	require.True(t, ok)
+	_, ok = cache.get(id("secret", 1))
+	require.True(t, ok)
+	require.Len(t, cache.entries, 2)
+}
+
+func TestSigningKeyCacheConcurrent(t *testing.T) {
+	cache := newSigningKeyCache(8)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				id := newSigningKeyID("AKID", "secret", "20150830", fmt.Sprint("region-", j%12), "s3")
+				expected := deriveSigningKey("secret", id.date, id.region, id.service)
+
+				if key, ok := cache.get(id); ok {
+					require.Equal(t, expected, key)
+					continue
+				}
+				cache.put(id, expected)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.LessOrEqual(t, cache.order.Len(), 8)
+	require.Len(t, cache.entries, cache.order.Len())
+}
+
+func TestSignSigningKeyCached(t *testing.T) {
+	signer := NewSigner("service")
+	signer.Now = func() time.Time { return suiteDate }
+
+	for i := 0; i < 2; i++ {
+		r, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", http.NoBody)
+		require.NoError(t, err)
+
+		require.NoError(t, signer.Sign(r, suiteCreds, "us-east-1"))
+		require.Contains(t, r.Header.Get("Authorization"), "Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31")
+	}
+
+	_, ok := keyCache.get(newSigningKeyID(suiteCreds.AccessKey, suiteCreds.SecretKey, "20150830", "us-east-1", "service"))
+	require.True(t, ok)
+}
+
+func BenchmarkSigningKey(b *testing.B) {
+	b.Run("Derive", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			deriveSigningKey(suiteCreds.SecretKey, "20150830", "us-east-1", "s3")
+		}
+	})
+
+	b.Run("Cached", func(b *testing.B) {
+		s := &signer{credentials: &suiteCreds, region: "us-east-1", service: "s3", date: suiteDate}
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s.computeSigningKey()
+		}
+	})
+}
+
+func BenchmarkSign(b *testing.B) {
+	r, err := http.NewRequest(http.MethodGet, "https://examplebucket.s3.amazonaws.com/photos/photo.jpg", http.NoBody)
+	require.NoError(b, err)
+	r.Header.Set("X-Amz-Content-Sha256", UnsignedPayload)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := Sign(r, suiteCreds, "us-east-1"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSignParallel(b *testing.B) {
+	b.ReportAllocs()
+
+	b.RunParallel(func(pb *testing.PB) {
+		r, err := http.NewRequest(http.MethodGet, "https://examplebucket.s3.amazonaws.com/photos/photo.jpg", http.NoBody)
+		require.NoError(b, err)
+		r.Header.Set("X-Amz-Content-Sha256", UnsignedPayload)
+
+		for pb.Next() {
+			if err := Sign(r, suiteCreds, "us-east-1"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
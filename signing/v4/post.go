@@ -0,0 +1,173 @@
+package signing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+)
+
+const postPolicyExpirationFormat = "2006-01-02T15:04:05.000Z"
+
+// PostPolicy is the policy document of a browser-based POST upload. Its
+// conditions restrict the form fields S3 accepts along with the signature.
+type PostPolicy struct {
+	Expiration time.Time
+
+	conditions []any
+
+	// fields are the exact matches, which the form must send as is.
+	fields map[string]string
+}
+
+func NewPostPolicy(expiration time.Time) *PostPolicy {
+	return &PostPolicy{Expiration: expiration}
+}
+
+// Equal requires the form field to be the value, replacing a previous exact
+// match of the field. It is returned by SignPostPolicy, except for the bucket
+// which is part of the URL.
+func (p *PostPolicy) Equal(field, value string) *PostPolicy {
+	condition := map[string]string{field: value}
+
+	replaced := false
+	for i, existing := range p.conditions {
+		if match, ok := existing.(map[string]string); ok {
+			if _, ok := match[field]; ok {
+				p.conditions[i] = condition
+				replaced = true
+				break
+			}
+		}
+	}
+
+	if !replaced {
+		p.conditions = append(p.conditions, condition)
+	}
+
+	if field != "bucket" {
+		if p.fields == nil {
+			p.fields = map[string]string{}
+		}
+		p.fields[field] = value
+	}
+
+	return p
+}
+
+// StartsWith requires the form field to start with the prefix. An empty prefix
+// allows any value.
+func (p *PostPolicy) StartsWith(field, prefix string) *PostPolicy {
+	p.conditions = append(p.conditions, []string{"starts-with", "$" + field, prefix})
+	return p
+}
+
+func (p *PostPolicy) SetBucket(bucket string) *PostPolicy {
+	return p.Equal("bucket", bucket)
+}
+
+func (p *PostPolicy) SetKey(key string) *PostPolicy {
+	return p.Equal("key", key)
+}
+
+func (p *PostPolicy) SetKeyStartsWith(prefix string) *PostPolicy {
+	return p.StartsWith("key", prefix)
+}
+
+func (p *PostPolicy) SetContentType(contentType string) *PostPolicy {
+	return p.Equal("Content-Type", contentType)
+}
+
+func (p *PostPolicy) SetContentTypeStartsWith(prefix string) *PostPolicy {
+	return p.StartsWith("Content-Type", prefix)
+}
+
+// SetContentLengthRange bounds the size of the uploaded file, in bytes.
+func (p *PostPolicy) SetContentLengthRange(minLength, maxLength int64) *PostPolicy {
+	p.conditions = append(p.conditions, []any{"content-length-range", minLength, maxLength})
+	return p
+}
+
+// SetMetadata requires the x-amz-meta- field of the user metadata name.
+func (p *PostPolicy) SetMetadata(name, value string) *PostPolicy {
+	return p.Equal("x-amz-meta-"+name, value)
+}
+
+// SetSuccessActionStatus selects the status S3 answers with: 200, 201 or the
+// default 204.
+func (p *PostPolicy) SetSuccessActionStatus(status int) *PostPolicy {
+	return p.Equal("success_action_status", strconv.Itoa(status))
+}
+
+func (p *PostPolicy) SetSuccessActionRedirect(url string) *PostPolicy {
+	return p.Equal("success_action_redirect", url)
+}
+
+// SignPostPolicy signs the policy for S3.
+func SignPostPolicy(p *PostPolicy, credentials config.Credentials, region string) (map[string]string, error) {
+	return s3Signer.SignPostPolicy(p, credentials, region)
+}
+
+// SignPostPolicy returns the form fields to embed: the exact matches of the
+// policy, the encoded policy and its signature. The file field, and the fields
+// only constrained by a prefix, are left to the form.
+func (s *Signer) SignPostPolicy(p *PostPolicy, credentials config.Credentials, region string) (map[string]string, error) {
+	now := s.now().UTC()
+
+	if p.Expiration.IsZero() {
+		return nil, errors.New("post policy expiration is required")
+	}
+
+	if !p.Expiration.After(now) {
+		return nil, fmt.Errorf("post policy already expired at %v", p.Expiration)
+	}
+
+	signer := &signer{
+		credentials: &credentials,
+		region:      region,
+		service:     s.service(),
+		date:        now,
+	}
+	signer.computeScope()
+
+	fields := make(map[string]string, len(p.fields)+6)
+	for name, value := range p.fields {
+		fields[name] = value
+	}
+
+	fields["x-amz-algorithm"] = algorithm
+	fields["x-amz-credential"] = credentials.AccessKey + "/" + signer.scope
+	fields["x-amz-date"] = now.Format(dateFormatISO8601)
+	if credentials.SessionToken != "" {
+		fields["x-amz-security-token"] = credentials.SessionToken
+	}
+
+	conditions := append([]any{}, p.conditions...)
+	for _, name := range []string{"x-amz-algorithm", "x-amz-credential", "x-amz-date", "x-amz-security-token"} {
+		if value, ok := fields[name]; ok {
+			conditions = append(conditions, map[string]string{name: value})
+		}
+	}
+
+	document, err := json.Marshal(struct {
+		Expiration string `json:"expiration"`
+		Conditions []any  `json:"conditions"`
+	}{
+		Expiration: p.Expiration.UTC().Format(postPolicyExpirationFormat),
+		Conditions: conditions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the post policy: %w", err)
+	}
+
+	policy := base64.StdEncoding.EncodeToString(document)
+
+	fields["policy"] = policy
+	fields["x-amz-signature"] = signer.computeSignature(signer.computeSigningKey(), policy)
+
+	return fields, nil
+}
@@ -0,0 +1,107 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignPostPolicy(t *testing.T) {
+	signer := &Signer{Now: func() time.Time { return time.Date(2015, time.December, 29, 0, 0, 0, 0, time.UTC) }}
+
+	policy := NewPostPolicy(time.Date(2015, time.December, 30, 12, 0, 0, 0, time.UTC)).
+		SetBucket("sigv4examplebucket").
+		SetKeyStartsWith("user/user1/").
+		SetContentTypeStartsWith("image/").
+		SetContentLengthRange(1, 10485760).
+		SetMetadata("uuid", "14365123651274").
+		SetSuccessActionStatus(201)
+
+	fields, err := signer.SignPostPolicy(policy, creds, "us-east-1")
+	require.NoError(t, err)
+
+	document, err := base64.StdEncoding.DecodeString(fields["policy"])
+	require.NoError(t, err)
+	require.JSONEq(
+		t,
+		`{
+			"expiration": "2015-12-30T12:00:00.000Z",
+			"conditions": [
+				{"bucket": "sigv4examplebucket"},
+				["starts-with", "$key", "user/user1/"],
+				["starts-with", "$Content-Type", "image/"],
+				["content-length-range", 1, 10485760],
+				{"x-amz-meta-uuid": "14365123651274"},
+				{"success_action_status": "201"},
+				{"x-amz-algorithm": "AWS4-HMAC-SHA256"},
+				{"x-amz-credential": "`+creds.AccessKey+`/20151229/us-east-1/s3/aws4_request"},
+				{"x-amz-date": "20151229T000000Z"}
+			]
+		}`,
+		string(document),
+	)
+
+	// The signing key is derived independently of the signer code.
+	key := []byte("AWS4" + creds.SecretKey)
+	for _, data := range []string{"20151229", "us-east-1", "s3", "aws4_request"} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(data))
+		key = mac.Sum(nil)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(fields["policy"]))
+
+	require.Equal(
+		t,
+		map[string]string{
+			"x-amz-meta-uuid":       "14365123651274",
+			"success_action_status": "201",
+			"x-amz-algorithm":       "AWS4-HMAC-SHA256",
+			"x-amz-credential":      creds.AccessKey + "/20151229/us-east-1/s3/aws4_request",
+			"x-amz-date":            "20151229T000000Z",
+			"policy":                fields["policy"],
+			"x-amz-signature":       hex.EncodeToString(mac.Sum(nil)),
+		},
+		fields,
+	)
+}
+
+func TestSignPostPolicySessionToken(t *testing.T) {
+	fields, err := SignPostPolicy(NewPostPolicy(time.Now().Add(time.Hour)).SetKey("key"), sessionCreds, "eu-west-1")
+	require.NoError(t, err)
+	require.Equal(t, sessionCreds.SessionToken, fields["x-amz-security-token"])
+	require.Equal(t, "key", fields["key"])
+
+	document, err := base64.StdEncoding.DecodeString(fields["policy"])
+	require.NoError(t, err)
+	require.Contains(t, string(document), `{"x-amz-security-token":"`+sessionCreds.SessionToken+`"}`)
+}
+
+func TestSignPostPolicyExpiration(t *testing.T) {
+	_, err := SignPostPolicy(&PostPolicy{}, creds, "us-east-1")
+	require.Error(t, err)
+
+	_, err = SignPostPolicy(NewPostPolicy(time.Now().Add(-time.Minute)), creds, "us-east-1")
+	require.ErrorContains(t, err, "expired")
+}
+
+func TestPostPolicyEqualReplaces(t *testing.T) {
+	policy := NewPostPolicy(time.Now().Add(time.Hour)).
+		SetKey("first").
+		SetContentTypeStartsWith("image/").
+		SetKey("second")
+
+	fields, err := SignPostPolicy(policy, creds, "us-east-1")
+	require.NoError(t, err)
+	require.Equal(t, "second", fields["key"])
+
+	document, err := base64.StdEncoding.DecodeString(fields["policy"])
+	require.NoError(t, err)
+	require.Contains(t, string(document), `"conditions":[{"key":"second"},["starts-with","$Content-Type","image/"],`)
+	require.NotContains(t, string(document), "first")
+}
@@ -0,0 +1,182 @@
+package signing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lvjp/raw-s3-sdk-go/signing/utils"
+)
+
+// StreamingPayload is the payload hash of requests whose body is sent as a
+// sequence of individually signed aws-chunked chunks. Setting it as the
+// X-Amz-Content-Sha256 header before signing streams the body instead of
+// reading it in memory. The request content length must be known.
+const StreamingPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+
+// ChunkSize is the size of the data carried by each chunk of a streaming
+// request, except the last ones.
+const ChunkSize = 64 * 1024
+
+const chunkAlgorithm = "AWS4-HMAC-SHA256-PAYLOAD"
+const chunkSignatureHeader = ";chunk-signature="
+const chunkSignatureLength = sha256.Size * 2
+const chunkCRLF = "\r\n"
+
+var emptySHA256 = utils.HexSha256("")
+
+func prepareStreaming(r *http.Request) error {
+	decodedLength := r.ContentLength
+	if raw := r.Header.Get("X-Amz-Decoded-Content-Length"); raw != "" {
+		var err error
+		decodedLength, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("cannot parse the header 'X-Amz-Decoded-Content-Length: %s': %w", raw, err)
+		}
+	}
+
+	if decodedLength < 0 {
+		return errors.New("streaming signature requires a known content length")
+	}
+
+	if r.Body == nil {
+		r.Body = http.NoBody
+	}
+
+	encodedLength := StreamingContentLength(decodedLength)
+
+	r.Header.Set("X-Amz-Decoded-Content-Length", strconv.FormatInt(decodedLength, 10))
+	r.Header.Set("Content-Length", strconv.FormatInt(encodedLength, 10))
+	r.ContentLength = encodedLength
+
+	if encoding := r.Header.Get("Content-Encoding"); encoding == "" {
+		r.Header.Set("Content-Encoding", "aws-chunked")
+	} else if !strings.Contains(encoding, "aws-chunked") {
+		r.Header.Set("Content-Encoding", "aws-chunked,"+encoding)
+	}
+
+	return nil
+}
+
+// StreamingContentLength returns the size on the wire of a streaming request
+// body carrying decodedLength bytes of data.
+func StreamingContentLength(decodedLength int64) int64 {
+	fullChunks := decodedLength / ChunkSize
+	length := fullChunks * chunkLength(ChunkSize)
+
+	if remaining := decodedLength % ChunkSize; remaining > 0 {
+		length += chunkLength(remaining)
+	}
+
+	return length + chunkLength(0)
+}
+
+func chunkLength(dataLength int64) int64 {
+	return int64(len(strconv.FormatInt(dataLength, 16))+len(chunkSignatureHeader)+chunkSignatureLength+len(chunkCRLF)) +
+		dataLength +
+		int64(len(chunkCRLF))
+}
+
+// chunkedReader encodes the body in signed chunks, each signature being
+// chained to the previous one, starting from the request signature.
+type chunkedReader struct {
+	body      io.ReadCloser
+	remaining int64
+
+	signer            *signer
+	signingKey        []byte
+	previousSignature string
+
+	data    []byte
+	encoded []byte
+	offset  int
+	done    bool
+}
+
+func newChunkedReader(s *signer, signingKey []byte, seedSignature string, decodedLength int64) *chunkedReader {
+	return &chunkedReader{
+		body:              s.request.Body,
+		remaining:         decodedLength,
+		signer:            s,
+		signingKey:        signingKey,
+		previousSignature: seedSignature,
+		data:              make([]byte, ChunkSize),
+		encoded:           make([]byte, 0, chunkLength(ChunkSize)),
+	}
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	for c.offset == len(c.encoded) {
+		if c.done {
+			return 0, io.EOF
+		}
+
+		if err := c.nextChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, c.encoded[c.offset:])
+	c.offset += n
+
+	return n, nil
+}
+
+func (c *chunkedReader) Close() error {
+	return c.body.Close()
+}
+
+func (c *chunkedReader) nextChunk() error {
+	size := int64(ChunkSize)
+	if c.remaining < size {
+		size = c.remaining
+	}
+
+	data := c.data[:size]
+	if _, err := io.ReadFull(c.body, data); err != nil {
+		return fmt.Errorf("cannot read the streaming payload: %w", err)
+	}
+
+	c.remaining -= size
+	c.done = size == 0
+
+	signature := c.signer.computeSignature(c.signingKey, c.computeStringToSign(data))
+	c.previousSignature = signature
+
+	c.encoded = c.encoded[:0]
+	c.encoded = strconv.AppendInt(c.encoded, size, 16)
+	c.encoded = append(c.encoded, chunkSignatureHeader...)
+	c.encoded = append(c.encoded, signature...)
+	c.encoded = append(c.encoded, chunkCRLF...)
+	c.encoded = append(c.encoded, data...)
+	c.encoded = append(c.encoded, chunkCRLF...)
+	c.offset = 0
+
+	return nil
+}
+
+func (c *chunkedReader) computeStringToSign(data []byte) string {
+	return computeChunkStringToSign(c.signer, c.previousSignature, data)
+}
+
+// computeChunkStringToSign chains the chunk signature to the previous one.
+func computeChunkStringToSign(s *signer, previousSignature string, data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return strings.Join(
+		[]string{
+			chunkAlgorithm,
+			s.date.Format(dateFormatISO8601),
+			s.scope,
+			previousSignature,
+			emptySHA256,
+			hex.EncodeToString(sum[:]),
+		},
+		"\n",
+	)
+}
@@ -0,0 +1,89 @@
+package signing
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSignStreaming uses the example of the "Signature Calculations for the
+// Authorization Header: Transferring Payload in Multiple Chunks" page of the
+// Amazon S3 documentation.
+func TestSignStreaming(t *testing.T) {
+	payload := bytes.Repeat([]byte{'a'}, 65*1024)
+
+	r := newRequest(
+		t,
+		http.MethodPut,
+		"http://s3.amazonaws.com/examplebucket/chunkObject.txt",
+		map[string]string{
+			"x-amz-date":           "20130524T000000Z",
+			"x-amz-storage-class":  "REDUCED_REDUNDANCY",
+			"x-amz-content-sha256": StreamingPayload,
+		},
+	)
+	r.Body = io.NopCloser(bytes.NewReader(payload))
+	r.ContentLength = int64(len(payload))
+
+	err := Sign(r, creds, "us-east-1")
+	require.NoError(t, err)
+
+	require.Equal(
+		t,
+		"AWS4-HMAC-SHA256 Credential="+creds.AccessKey+"/20130524/us-east-1/s3/aws4_request,"+
+			"SignedHeaders=content-encoding;content-length;host;x-amz-content-sha256;x-amz-date;x-amz-decoded-content-length;x-amz-storage-class,"+
+			"Signature=4f232c4386841ef735655705268965c44a0e4690baa4adea153f7db9fa80a0a9",
+		r.Header.Get("Authorization"),
+	)
+	require.Equal(t, "aws-chunked", r.Header.Get("Content-Encoding"))
+	require.Equal(t, "66560", r.Header.Get("X-Amz-Decoded-Content-Length"))
+	require.Equal(t, "66824", r.Header.Get("Content-Length"))
+	require.Equal(t, int64(66824), r.ContentLength)
+
+	encoded, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	require.Len(t, encoded, 66824)
+
+	expected := strings.Join(
+		[]string{
+			"10000;chunk-signature=ad80c730a21e5b8d04586a2213dd63b9a0e99e0e2307b0ade35a65485a288648\r\n" + string(payload[:65536]) + "\r\n",
+			"400;chunk-signature=0055627c9e194cb4542bae2aa5492e3c1575bbb81b612b7d234b86a503ef5497\r\n" + string(payload[65536:]) + "\r\n",
+			"0;chunk-signature=b6c6ea8a5354eaf15b3cb7646744f4275b71ea724fed81ceb9323e279d449df9\r\n\r\n",
+		},
+		"",
+	)
+	require.Equal(t, expected, string(encoded))
+}
+
+func TestSignStreamingShortBody(t *testing.T) {
+	r := newRequest(
+		t,
+		http.MethodPut,
+		"http://s3.amazonaws.com/examplebucket/chunkObject.txt",
+		map[string]string{"x-amz-content-sha256": StreamingPayload},
+	)
+	r.Body = io.NopCloser(strings.NewReader("too short"))
+	r.ContentLength = 100
+
+	require.NoError(t, Sign(r, creds, "us-east-1"))
+
+	_, err := io.ReadAll(r.Body)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestSignStreamingUnknownLength(t *testing.T) {
+	r := newRequest(
+		t,
+		http.MethodPut,
+		"http://s3.amazonaws.com/examplebucket/chunkObject.txt",
+		map[string]string{"x-amz-content-sha256": StreamingPayload},
+	)
+	r.Body = io.NopCloser(strings.NewReader("unknown"))
+	r.ContentLength = -1
+
+	require.Error(t, Sign(r, creds, "us-east-1"))
+}
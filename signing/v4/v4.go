@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,40 +22,207 @@ import (
 const dateFormatYYYMMDD = "20060102"
 const dateFormatISO8601 = "20060102T150405Z"
 
+const algorithm = "AWS4-HMAC-SHA256"
+
+const serviceS3 = "s3"
+
+// UnsignedPayload is the payload hash of requests whose body is not signed.
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// MaxPresignExpiration is the longest validity S3 accepts for a presigned URL.
+const MaxPresignExpiration = 7 * 24 * time.Hour
+
+// Signer signs requests with the AWS Signature Version 4. Its zero value signs
+// S3 requests, use NewSigner for the other services.
+type Signer struct {
+	// Service is the signing name of the service, s3 when empty.
+	Service string
+
+	// IncludeHeader tells whether a header, whose name is given in lowercase,
+	// is part of the signature. The host header is always signed. S3Headers is
+	// used when nil.
+	IncludeHeader func(name string) bool
+
+	// DoubleURIEncode encodes the path twice in the canonical request, as every
+	// service but S3 expects.
+	DoubleURIEncode bool
+
+	// DisableContentSHA256Header signs the payload hash without sending it as
+	// the X-Amz-Content-Sha256 header, which only S3 requires.
+	DisableContentSHA256Header bool
+
+	// Now is the time source, time.Now when nil.
+	Now func() time.Time
+}
+
+// NewSigner returns a signer following the generic SigV4 rules, which sign
+// every header and encode the path twice.
+func NewSigner(service string) *Signer {
+	return &Signer{
+		Service:                    service,
+		IncludeHeader:              AllHeaders,
+		DoubleURIEncode:            true,
+		DisableContentSHA256Header: true,
+	}
+}
+
+// S3Headers is the header inclusion rule of S3 requests.
+func S3Headers(name string) bool {
+	switch name {
+	case "content-encoding", "content-length", "content-md5", "content-type", "date", "range":
+		return true
+	default:
+		return strings.HasPrefix(name, "x-amz-")
+	}
+}
+
+// AllHeaders signs every header but the ones proxies may add or change.
+func AllHeaders(name string) bool {
+	switch name {
+	case "authorization", "user-agent", "expect", "x-amzn-trace-id":
+		return false
+	default:
+		return true
+	}
+}
+
+var s3Signer = &Signer{}
+
 func Sign(r *http.Request, credentials config.Credentials, region string) error {
-	if err := prepareRequest(r); err != nil {
+	return s3Signer.Sign(r, credentials, region)
+}
+
+// Presign adds the query string authentication parameters to the request URL
+// so that it can be sent, until it expires, without the credentials. The
+// payload is not signed, and the headers of the request which are part of the
+// signature must be sent along with the URL.
+func Presign(r *http.Request, credentials config.Credentials, region string, expires time.Duration) error {
+	return s3Signer.Presign(r, credentials, region, expires)
+}
+
+func presign(r *http.Request, credentials config.Credentials, region string, expires time.Duration, now time.Time) error {
+	return s3Signer.presign(r, credentials, region, expires, now)
+}
+
+func (s *Signer) Sign(r *http.Request, credentials config.Credentials, region string) error {
+	payloadHash, err := s.prepareRequest(r)
+	if err != nil {
 		return err
 	}
 
-	signer := &signer{
-		request:     r,
-		credentials: &credentials,
-		region:      region,
-		queryString: r.URL.Query(),
+	if credentials.SessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
 	}
 
+	signer := s.newSigner(r, &credentials, region, payloadHash)
+
 	if err := signer.extractDate(); err != nil {
 		return err
 	}
 
 	signer.computeScope()
+	signer.computeCanonicalHeaders()
+
+	signingKey := signer.computeSigningKey()
+	signature := signer.computeSignature(signingKey, signer.computeStringToSign())
+
+	r.Header.Set("Authorization", signer.computeAuthorizationheader(signature))
+
+	if signer.payloadHash == StreamingPayload {
+		decodedLength, err := strconv.ParseInt(r.Header.Get("X-Amz-Decoded-Content-Length"), 10, 64)
+		if err != nil {
+			return err
+		}
+
+		r.Body = newChunkedReader(signer, signingKey, signature, decodedLength)
+	}
+
+	return nil
+}
+
+// Presign works as the package level Presign, for the service of the signer.
+func (s *Signer) Presign(r *http.Request, credentials config.Credentials, region string, expires time.Duration) error {
+	return s.presign(r, credentials, region, expires, s.now())
+}
+
+func (s *Signer) presign(r *http.Request, credentials config.Credentials, region string, expires time.Duration, now time.Time) error {
+	if expires < time.Second || expires > MaxPresignExpiration {
+		return fmt.Errorf("presign expiration must be between 1s and %v, got %v", MaxPresignExpiration, expires)
+	}
+
+	if r.Header.Get("Host") == "" {
+		r.Header.Set("Host", r.Host)
+	}
+
+	if r.URL.Path == "" {
+		r.URL.Path = "/"
+	}
+
+	signer := s.newSigner(r, &credentials, region, UnsignedPayload)
+	signer.date = now.UTC()
+
+	signer.computeScope()
+	signer.computeCanonicalHeaders()
+
+	signer.queryString.Set("X-Amz-Algorithm", algorithm)
+	signer.queryString.Set("X-Amz-Credential", credentials.AccessKey+"/"+signer.scope)
+	signer.queryString.Set("X-Amz-Date", signer.date.Format(dateFormatISO8601))
+	signer.queryString.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	signer.queryString.Set("X-Amz-SignedHeaders", signer.signedHeaders)
+	if credentials.SessionToken != "" {
+		signer.queryString.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
 
-	auth := signer.computeAuthorizationheader(
-		signer.computeSignature(
-			signer.computeSigningKey(),
-			signer.computeStringToSign(),
-		),
+	signature := signer.computeSignature(
+		signer.computeSigningKey(),
+		signer.computeStringToSign(),
 	)
 
-	r.Header.Set("Authorization", auth)
+	signer.queryString.Set("X-Amz-Signature", signature)
+	r.URL.RawQuery = utils.CanonicalQueryString(signer.queryString)
 
 	return nil
 }
 
-func prepareRequest(r *http.Request) error {
+func (s *Signer) now() time.Time {
+	if s.Now == nil {
+		return time.Now()
+	}
+
+	return s.Now()
+}
+
+func (s *Signer) service() string {
+	if s.Service == "" {
+		return serviceS3
+	}
+
+	return s.Service
+}
+
+func (s *Signer) newSigner(r *http.Request, credentials *config.Credentials, region, payloadHash string) *signer {
+	includeHeader := s.IncludeHeader
+	if includeHeader == nil {
+		includeHeader = S3Headers
+	}
+
+	return &signer{
+		request:         r,
+		credentials:     credentials,
+		region:          region,
+		service:         s.service(),
+		includeHeader:   includeHeader,
+		doubleURIEncode: s.DoubleURIEncode,
+		queryString:     r.URL.Query(),
+		payloadHash:     payloadHash,
+	}
+}
+
+// prepareRequest sets the default headers, and returns the payload hash.
+func (s *Signer) prepareRequest(r *http.Request) (string, error) {
 	defaults := map[string]string{
 		"Host":       r.Host,
-		"X-Amz-Date": time.Now().UTC().Format(dateFormatISO8601),
+		"X-Amz-Date": s.now().UTC().Format(dateFormatISO8601),
 	}
 
 	for name, value := range defaults {
@@ -63,33 +231,47 @@ func prepareRequest(r *http.Request) error {
 		}
 	}
 
-	if r.Header.Get("X-Amz-Content-Sha256") == "" {
+	if r.URL.Path == "" {
+		r.URL.Path = "/"
+	}
+
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+
+	switch payloadHash {
+	case "":
 		payload, err := readAndReplaceBody(r)
 		if err != nil {
-			return err
+			return "", err
+		}
+		payloadHash = fmt.Sprintf("%x", sha256.Sum256(payload))
+		if !s.DisableContentSHA256Header {
+			r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+		}
+	case StreamingPayload:
+		if err := prepareStreaming(r); err != nil {
+			return "", err
 		}
-		hash := fmt.Sprintf("%x", sha256.Sum256(payload))
-		r.Header.Set("X-Amz-Content-Sha256", hash)
-	}
-
-	if r.URL.Path == "" {
-		r.URL.Path = "/"
 	}
 
-	return nil
+	return payloadHash, nil
 }
 
 type signer struct {
 	// Input
-	request     *http.Request
-	credentials *config.Credentials
-	region      string
-	queryString url.Values
+	request         *http.Request
+	credentials     *config.Credentials
+	region          string
+	service         string
+	includeHeader   func(name string) bool
+	doubleURIEncode bool
+	queryString     url.Values
+	payloadHash     string
 
 	// Cached computed values
-	date          time.Time
-	scope         string
-	signedHeaders string
+	date             time.Time
+	scope            string
+	canonicalHeaders string
+	signedHeaders    string
 }
 
 func (s *signer) extractDate() (err error) {
@@ -103,12 +285,12 @@ func (s *signer) extractDate() (err error) {
 		}
 	}
 
-	if raw := s.queryString.Get("x-amz-date"); raw != "" {
+	if raw := s.queryString.Get("X-Amz-Date"); raw != "" {
 		s.date, err = time.Parse(dateFormatISO8601, raw)
 		if err != nil {
-			err = fmt.Errorf("cannot parse the header query parameter 'x-amz-date': %w", err)
-			return
+			err = fmt.Errorf("cannot parse the header query parameter 'X-Amz-Date': %w", err)
 		}
+		return
 	}
 
 	return errors.New("cannot find date for the signature")
@@ -116,17 +298,37 @@ func (s *signer) extractDate() (err error) {
 
 func (s *signer) computeScope() {
 	s.scope = fmt.Sprintf(
-		"%s/%s/s3/aws4_request",
+		"%s/%s/%s/aws4_request",
 		s.date.Format(dateFormatYYYMMDD),
 		s.region,
+		s.service,
 	)
 }
 
 func (s *signer) computeSigningKey() []byte {
-	res := []byte("AWS4" + s.credentials.SecretKey)
+	id := newSigningKeyID(
+		s.credentials.AccessKey,
+		s.credentials.SecretKey,
+		s.date.Format(dateFormatYYYMMDD),
+		s.region,
+		s.service,
+	)
+
+	if key, ok := keyCache.get(id); ok {
+		return key
+	}
+
+	// Concurrent misses derive the same key, so the last stored one wins.
+	key := deriveSigningKey(s.credentials.SecretKey, id.date, id.region, id.service)
+	keyCache.put(id, key)
 
-	toSign := []string{s.date.Format(dateFormatYYYMMDD), s.region, "s3", "aws4_request"}
-	for _, data := range toSign {
+	return key
+}
+
+func deriveSigningKey(secretKey, date, region, service string) []byte {
+	res := []byte("AWS4" + secretKey)
+
+	for _, data := range []string{date, region, service, "aws4_request"} {
 		res = utils.HMacSha256(res, data)
 	}
 
@@ -137,19 +339,28 @@ func (s *signer) computeCanonicalRequest() string {
 	return strings.Join(
 		[]string{
 			s.request.Method,
-			utils.URIEncode(s.request.URL.Path),
+			s.computeCanonicalURI(),
 			s.computeCanonicalQueryString(),
-			s.computeCanonicalHeaders(),
-			s.request.Header.Get("X-Amz-Content-Sha256"),
+			s.canonicalHeaders,
+			s.payloadHash,
 		},
 		"\n",
 	)
 }
 
+func (s *signer) computeCanonicalURI() string {
+	uri := utils.URIEncode(s.request.URL.Path, false)
+	if s.doubleURIEncode {
+		uri = utils.URIEncode(uri, false)
+	}
+
+	return uri
+}
+
 func (s *signer) computeStringToSign() string {
 	return strings.Join(
 		[]string{
-			"AWS4-HMAC-SHA256",
+			algorithm,
 			s.date.Format(dateFormatISO8601),
 			s.scope,
 			utils.HexSha256(s.computeCanonicalRequest()),
@@ -164,7 +375,8 @@ func (s *signer) computeSignature(signingKey []byte, stringToSign string) string
 
 func (s *signer) computeAuthorizationheader(signature string) string {
 	return fmt.Sprintf(
-		"AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		"%s Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		algorithm,
 		s.credentials.AccessKey,
 		s.scope,
 		s.signedHeaders,
@@ -173,28 +385,10 @@ func (s *signer) computeAuthorizationheader(signature string) string {
 }
 
 func (s *signer) computeCanonicalQueryString() string {
-	keys := maps.Keys(s.queryString)
-	sort.Strings(keys)
-
-	buf := strings.Builder{}
-	for _, key := range keys {
-		values := s.queryString[key]
-		keyEscaped := utils.URIEncode(key)
-
-		for _, value := range values {
-			if buf.Len() > 0 {
-				buf.WriteByte('&')
-			}
-			buf.WriteString(keyEscaped)
-			buf.WriteByte('=')
-			buf.WriteString(utils.URIEncode(value))
-		}
-	}
-
-	return buf.String()
+	return utils.CanonicalQueryString(s.queryString)
 }
 
-func (s *signer) computeCanonicalHeaders() string {
+func (s *signer) computeCanonicalHeaders() {
 	headers := make(map[string][]string, len(s.request.Header))
 
 	for key, values := range s.request.Header {
@@ -213,10 +407,8 @@ func (s *signer) computeCanonicalHeaders() string {
 				cleaned = append(cleaned, value)
 			}
 			headers[name] = cleaned
-		case "content-type", "date", "range":
-			headers[name] = values
 		default:
-			if strings.HasPrefix(name, "x-amz-") {
+			if s.includeHeader(name) {
 				headers[name] = values
 			}
 		}
@@ -228,21 +420,22 @@ func (s *signer) computeCanonicalHeaders() string {
 
 	buf := strings.Builder{}
 	for _, key := range keys {
-		values := headers[key]
-		keyEscaped := utils.URIEncode(key)
-
-		for _, value := range values {
-			buf.WriteString(keyEscaped)
-			buf.WriteByte(':')
-			buf.WriteString(strings.TrimSpace(value))
-			buf.WriteByte('\n')
+		values := make([]string, 0, len(headers[key]))
+		for _, value := range headers[key] {
+			// Sequential spaces are folded, as proxies may do.
+			values = append(values, strings.Join(strings.Fields(value), " "))
 		}
+
+		buf.WriteString(utils.URIEncode(key, true))
+		buf.WriteByte(':')
+		buf.WriteString(strings.Join(values, ","))
+		buf.WriteByte('\n')
 	}
 
 	buf.WriteByte('\n')
 	buf.WriteString(s.signedHeaders)
 
-	return buf.String()
+	s.canonicalHeaders = buf.String()
 }
 
 func readAndReplaceBody(r *http.Request) ([]byte, error) {
@@ -255,6 +448,12 @@ func readAndReplaceBody(r *http.Request) ([]byte, error) {
 		return nil, fmt.Errorf("cannot read and replace the request body: %w", err)
 	}
 
-	r.Body = io.NopCloser(bytes.NewReader(payload))
+	if len(payload) == 0 {
+		r.Body = http.NoBody
+	} else {
+		r.Body = io.NopCloser(bytes.NewReader(payload))
+	}
+	r.ContentLength = int64(len(payload))
+
 	return payload, nil
 }
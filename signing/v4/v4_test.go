@@ -1,9 +1,16 @@
 package signing
 
 import (
+	"context"
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsv4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/lvjp/raw-s3-sdk-go/config"
 	"github.com/stretchr/testify/require"
 )
@@ -13,6 +20,12 @@ var creds = config.Credentials{
 	SecretKey: "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY",
 }
 
+var sessionCreds = config.Credentials{
+	AccessKey:    "ASI" + "AIOSFODNN7EXAMPLE",
+	SecretKey:    "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY",
+	SessionToken: "IQoJb3JpZ2luX2VjEMv//////////wEaCXVzLWVhc3QtMSJGMEQCIBSUbVdj9YGs2g0HkHsOHFdkwOozjARSKHL987NhhOC8AiBPepRU1obMvIbGU0T+WphFPgK/qpxaf5Snvm5M57XFkCqlAgjz",
+}
+
 func TestSign(t *testing.T) {
 	for _, tc := range generateTestCases(t) {
 		t.Run(tc.name, func(t *testing.T) {
@@ -112,3 +125,196 @@ func generateTestCases(t *testing.T) []testCase {
 		},
 	}
 }
+
+func TestPresign(t *testing.T) {
+	r := newRequest(t, http.MethodGet, "https://examplebucket.s3.amazonaws.com/test.txt", nil)
+
+	err := presign(r, creds, "us-east-1", 24*time.Hour, time.Date(2013, time.May, 24, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	require.Equal(
+		t,
+		url.Values{
+			"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+			"X-Amz-Credential":    {creds.AccessKey + "/20130524/us-east-1/s3/aws4_request"},
+			"X-Amz-Date":          {"20130524T000000Z"},
+			"X-Amz-Expires":       {"86400"},
+			"X-Amz-SignedHeaders": {"host"},
+			"X-Amz-Signature":     {"aeeed9bbccd4d02ee5c0109b86d86835f995330da4c265957d157751f604d404"},
+		},
+		r.URL.Query(),
+	)
+	require.Empty(t, r.Header.Get("Authorization"))
+}
+
+func TestPresignExpiration(t *testing.T) {
+	for _, expires := range []time.Duration{0, time.Millisecond, MaxPresignExpiration + time.Second} {
+		r := newRequest(t, http.MethodGet, "https://examplebucket.s3.amazonaws.com/test.txt", nil)
+		require.Error(t, Presign(r, creds, "us-east-1", expires), expires)
+	}
+}
+
+func TestPresignMatchesAWS(t *testing.T) {
+	for name, credentials := range map[string]config.Credentials{"Static": creds, "SessionToken": sessionCreds} {
+		t.Run(name, func(t *testing.T) {
+			cfg := config.Config{
+				Region:        "eu-west-3",
+				Endpoint:      config.Endpoint{Host: "s3.example.com", Port: 443, WithSSL: true},
+				Credentials:   credentials,
+				SignatureType: config.SignatureTypeV4,
+			}
+
+			presignClient := s3.NewPresignClient(s3.NewFromConfig(cfg.ToAWS()))
+
+			bucket := "my-bucket"
+			key := "dir/some file+name.txt"
+
+			awsReq, err := presignClient.PresignGetObject(
+				context.Background(),
+				&s3.GetObjectInput{
+					Bucket:                     &bucket,
+					Key:                        &key,
+					ResponseContentDisposition: aws.String("attachment"),
+				},
+				s3.WithPresignExpires(15*time.Minute),
+			)
+			require.NoError(t, err)
+
+			awsURL, err := url.Parse(awsReq.URL)
+			require.NoError(t, err)
+
+			awsQuery := awsURL.Query()
+			date, err := time.Parse(dateFormatISO8601, awsQuery.Get("X-Amz-Date"))
+			require.NoError(t, err)
+
+			ourURL := *awsURL
+			ourQuery := url.Values{}
+			for name, values := range awsQuery {
+				if !strings.HasPrefix(name, "X-Amz-") {
+					ourQuery[name] = values
+				}
+			}
+			ourURL.RawQuery = ourQuery.Encode()
+
+			r := newRequest(t, http.MethodGet, ourURL.String(), nil)
+			err = presign(r, credentials, cfg.Region, 15*time.Minute, date)
+			require.NoError(t, err)
+
+			require.Equal(t, awsURL.EscapedPath(), r.URL.EscapedPath())
+			require.Equal(t, awsQuery, r.URL.Query())
+		})
+	}
+}
+
+func TestSignSessionTokenMatchesAWS(t *testing.T) {
+	const rawURL = "https://examplebucket.s3.amazonaws.com/photos/my%20photo.jpg"
+	const payloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	date := time.Date(2023, time.March, 1, 12, 30, 0, 0, time.UTC)
+
+	awsReq := newRequest(t, http.MethodGet, rawURL, map[string]string{"X-Amz-Content-Sha256": payloadHash})
+	err := awsv4.NewSigner().SignHTTP(
+		context.Background(),
+		aws.Credentials{
+			AccessKeyID:     sessionCreds.AccessKey,
+			SecretAccessKey: sessionCreds.SecretKey,
+			SessionToken:    sessionCreds.SessionToken,
+		},
+		awsReq,
+		payloadHash,
+		"s3",
+		"eu-west-1",
+		date,
+		func(o *awsv4.SignerOptions) { o.DisableURIPathEscaping = true },
+	)
+	require.NoError(t, err)
+
+	ourReq := newRequest(
+		t,
+		http.MethodGet,
+		rawURL,
+		map[string]string{
+			"X-Amz-Content-Sha256": payloadHash,
+			"X-Amz-Date":           date.Format(dateFormatISO8601),
+		},
+	)
+	err = Sign(ourReq, sessionCreds, "eu-west-1")
+	require.NoError(t, err)
+
+	require.Equal(t, sessionCreds.SessionToken, ourReq.Header.Get("X-Amz-Security-Token"))
+	require.Equal(
+		t,
+		strings.ReplaceAll(awsReq.Header.Get("Authorization"), ", ", ","),
+		ourReq.Header.Get("Authorization"),
+	)
+}
+
+func TestSignerMatchesAWS(t *testing.T) {
+	const rawURL = "https://example.amazonaws.com/example%20space/%E1%88%B4"
+	const payloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	date := time.Date(2023, time.March, 1, 12, 30, 0, 0, time.UTC)
+	headers := map[string]string{
+		"Content-Type":         "application/x-www-form-urlencoded",
+		"X-Amz-Content-Sha256": payloadHash,
+	}
+
+	awsReq := newRequest(t, http.MethodPost, rawURL, headers)
+	err := awsv4.NewSigner().SignHTTP(
+		context.Background(),
+		aws.Credentials{AccessKeyID: creds.AccessKey, SecretAccessKey: creds.SecretKey},
+		awsReq,
+		payloadHash,
+		"sts",
+		"us-east-1",
+		date,
+	)
+	require.NoError(t, err)
+
+	ourReq := newRequest(t, http.MethodPost, rawURL, headers)
+	ourReq.Header.Set("X-Amz-Date", date.Format(dateFormatISO8601))
+	err = NewSigner("sts").Sign(ourReq, creds, "us-east-1")
+	require.NoError(t, err)
+
+	require.Contains(t, ourReq.Header.Get("Authorization"), "/us-east-1/sts/aws4_request")
+	require.Equal(
+		t,
+		strings.ReplaceAll(awsReq.Header.Get("Authorization"), ", ", ","),
+		ourReq.Header.Get("Authorization"),
+	)
+}
+
+func TestSignRepeatedQueryMatchesAWS(t *testing.T) {
+	const rawURL = "https://examplebucket.s3.amazonaws.com/?prefix=b&prefix=a%20b&prefix=A&list-type=2"
+	const payloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	date := time.Date(2023, time.March, 1, 12, 30, 0, 0, time.UTC)
+
+	awsReq := newRequest(t, http.MethodGet, rawURL, map[string]string{"X-Amz-Content-Sha256": payloadHash})
+	err := awsv4.NewSigner().SignHTTP(
+		context.Background(),
+		aws.Credentials{AccessKeyID: creds.AccessKey, SecretAccessKey: creds.SecretKey},
+		awsReq,
+		payloadHash,
+		"s3",
+		"us-east-1",
+		date,
+		func(o *awsv4.SignerOptions) { o.DisableURIPathEscaping = true },
+	)
+	require.NoError(t, err)
+
+	ourReq := newRequest(
+		t,
+		http.MethodGet,
+		rawURL,
+		map[string]string{
+			"X-Amz-Content-Sha256": payloadHash,
+			"X-Amz-Date":           date.Format(dateFormatISO8601),
+		},
+	)
+	err = Sign(ourReq, creds, "us-east-1")
+	require.NoError(t, err)
+
+	require.Equal(
+		t,
+		strings.ReplaceAll(awsReq.Header.Get("Authorization"), ", ", ","),
+		ourReq.Header.Get("Authorization"),
+	)
+}
@@ -0,0 +1,468 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+)
+
+// DefaultMaxClockSkew is the largest difference S3 accepts between the date of
+// a request and the server clock.
+const DefaultMaxClockSkew = 15 * time.Minute
+
+// maxUnhashedPayloadSize bounds the body buffered to hash it when a request
+// does not declare its payload hash.
+const maxUnhashedPayloadSize = 1024 * 1024
+
+// VerificationError is returned by Verify. Code and StatusCode are the ones S3
+// answers with, so that a gateway can forward them to its clients.
+type VerificationError struct {
+	Code       string
+	StatusCode int
+	Message    string
+
+	// Err is the cause, such as the error of the credentials lookup.
+	Err error
+}
+
+func (e *VerificationError) Error() string {
+	msg := e.Code + ": " + e.Message
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the errors sharing the same code, so that errors.Is can be used
+// with the Err* values.
+func (e *VerificationError) Is(target error) bool {
+	t, ok := target.(*VerificationError)
+	return ok && t.Code == e.Code
+}
+
+// The Err* values are the codes Verify fails with. Verify returns copies of
+// them, which never alias these values, to be matched with errors.Is.
+var (
+	ErrAccessDenied = &VerificationError{
+		Code:       "AccessDenied",
+		StatusCode: http.StatusForbidden,
+		Message:    "Access Denied",
+	}
+	ErrAuthorizationHeaderMalformed = &VerificationError{
+		Code:       "AuthorizationHeaderMalformed",
+		StatusCode: http.StatusBadRequest,
+		Message:    "The authorization header is malformed",
+	}
+	ErrAuthorizationQueryParametersError = &VerificationError{
+		Code:       "AuthorizationQueryParametersError",
+		StatusCode: http.StatusBadRequest,
+		Message:    "The authorization query parameters are malformed",
+	}
+	ErrIncompleteBody = &VerificationError{
+		Code:       "IncompleteBody",
+		StatusCode: http.StatusBadRequest,
+		Message:    "The request body does not match the announced length",
+	}
+	ErrInvalidAccessKeyID = &VerificationError{
+		Code:       "InvalidAccessKeyId",
+		StatusCode: http.StatusForbidden,
+		Message:    "The AWS access key Id you provided does not exist in our records",
+	}
+	ErrInvalidRequest = &VerificationError{
+		Code:       "InvalidRequest",
+		StatusCode: http.StatusBadRequest,
+		Message:    "Invalid request",
+	}
+	ErrInvalidToken = &VerificationError{
+		Code:       "InvalidToken",
+		StatusCode: http.StatusBadRequest,
+		Message:    "The provided token is malformed or otherwise invalid",
+	}
+	ErrRequestTimeTooSkewed = &VerificationError{
+		Code:       "RequestTimeTooSkewed",
+		StatusCode: http.StatusForbidden,
+		Message:    "The difference between the request time and the current time is too large",
+	}
+	ErrSignatureDoesNotMatch = &VerificationError{
+		Code:       "SignatureDoesNotMatch",
+		StatusCode: http.StatusForbidden,
+		Message:    "The request signature we calculated does not match the signature you provided",
+	}
+	ErrXAmzContentSHA256Mismatch = &VerificationError{
+		Code:       "XAmzContentSHA256Mismatch",
+		StatusCode: http.StatusBadRequest,
+		Message:    "The provided 'x-amz-content-sha256' header does not match what was computed",
+	}
+)
+
+func newVerificationError(base *VerificationError, format string, args ...any) *VerificationError {
+	err := copyVerificationError(base)
+	err.Message = fmt.Sprintf(format, args...)
+
+	return err
+}
+
+// copyVerificationError returns a copy of base, so that callers modifying the
+// returned error do not alter the Err* values.
+func copyVerificationError(base *VerificationError) *VerificationError {
+	err := *base
+	return &err
+}
+
+// CredentialsLookup returns the credentials of an access key.
+type CredentialsLookup func(accessKey string) (config.Credentials, error)
+
+// Verifier checks the signature of incoming requests. Its zero value verifies
+// S3 requests.
+type Verifier struct {
+	// Service is the expected signing name, s3 when empty.
+	Service string
+
+	// DoubleURIEncode must match the one of the signers.
+	DoubleURIEncode bool
+
+	// MaxClockSkew is DefaultMaxClockSkew when zero.
+	MaxClockSkew time.Duration
+
+	// Now is the time source, time.Now when nil.
+	Now func() time.Time
+}
+
+// Verify checks a request with the default verifier.
+func Verify(r *http.Request, lookup CredentialsLookup) error {
+	return (&Verifier{}).Verify(r, lookup)
+}
+
+// authorization holds the signature parameters of a request, from its
+// Authorization header or its presigned query.
+type authorization struct {
+	accessKey     string
+	scope         string
+	region        string
+	signedHeaders []string
+	signature     string
+	presigned     bool
+	expires       time.Duration
+}
+
+// Verify checks the signature, date and session token of the request. When
+// the payload is signed, the request body is replaced by one checking it as
+// it is read: its Read method returns a *VerificationError instead of io.EOF
+// when the payload does not match, so the handler must consume the body
+// before committing to the request.
+func (v *Verifier) Verify(r *http.Request, lookup CredentialsLookup) error {
+	auth, err := parseAuthorization(r)
+	if err != nil {
+		return err
+	}
+
+	date, err := v.checkDate(r, auth)
+	if err != nil {
+		return err
+	}
+
+	credentials, err := lookup(auth.accessKey)
+	if err != nil {
+		verr := copyVerificationError(ErrInvalidAccessKeyID)
+		verr.Err = err
+		return verr
+	}
+
+	signer, err := v.newSigner(r, auth, &credentials, date)
+	if err != nil {
+		return err
+	}
+
+	if signer.scope != auth.scope {
+		return newVerificationError(ErrAuthorizationHeaderMalformed, "unexpected credential scope %q", auth.scope)
+	}
+
+	signer.computeCanonicalHeaders()
+	signingKey := signer.computeSigningKey()
+	signature := signer.computeSignature(signingKey, signer.computeStringToSign())
+
+	if !hmac.Equal([]byte(signature), []byte(auth.signature)) {
+		return copyVerificationError(ErrSignatureDoesNotMatch)
+	}
+
+	if credentials.SessionToken != "" {
+		token := r.Header.Get("X-Amz-Security-Token")
+		if auth.presigned {
+			token = signer.queryString.Get("X-Amz-Security-Token")
+		}
+
+		if !hmac.Equal([]byte(token), []byte(credentials.SessionToken)) {
+			return copyVerificationError(ErrInvalidToken)
+		}
+	}
+
+	return v.wrapBody(r, signer, signingKey, signature)
+}
+
+func (v *Verifier) service() string {
+	if v.Service == "" {
+		return serviceS3
+	}
+
+	return v.Service
+}
+
+func (v *Verifier) now() time.Time {
+	if v.Now == nil {
+		return time.Now()
+	}
+
+	return v.Now()
+}
+
+func parseAuthorization(r *http.Request) (*authorization, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		return parseAuthorizationHeader(header)
+	}
+
+	if r.URL.Query().Has("X-Amz-Algorithm") {
+		return parseAuthorizationQuery(r)
+	}
+
+	return nil, newVerificationError(ErrAccessDenied, "the request is not signed")
+}
+
+func parseAuthorizationHeader(header string) (*authorization, error) {
+	params, found := strings.CutPrefix(header, algorithm+" ")
+	if !found {
+		return nil, newVerificationError(ErrAuthorizationHeaderMalformed, "unsupported algorithm")
+	}
+
+	values := map[string]string{}
+	for _, param := range strings.Split(params, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok {
+			return nil, newVerificationError(ErrAuthorizationHeaderMalformed, "malformed parameter %q", param)
+		}
+		values[name] = value
+	}
+
+	auth, err := newAuthorization(values["Credential"], values["SignedHeaders"], values["Signature"])
+	if err != nil {
+		return nil, newVerificationError(ErrAuthorizationHeaderMalformed, "%v", err)
+	}
+
+	return auth, nil
+}
+
+func parseAuthorizationQuery(r *http.Request) (*authorization, error) {
+	query := r.URL.Query()
+
+	if query.Get("X-Amz-Algorithm") != algorithm {
+		return nil, newVerificationError(ErrAuthorizationQueryParametersError, "unsupported algorithm")
+	}
+
+	auth, err := newAuthorization(query.Get("X-Amz-Credential"), query.Get("X-Amz-SignedHeaders"), query.Get("X-Amz-Signature"))
+	if err != nil {
+		return nil, newVerificationError(ErrAuthorizationQueryParametersError, "%v", err)
+	}
+
+	seconds, err := strconv.ParseInt(query.Get("X-Amz-Expires"), 10, 64)
+	auth.expires = time.Duration(seconds) * time.Second
+	if err != nil || auth.expires < time.Second || auth.expires > MaxPresignExpiration {
+		return nil, newVerificationError(
+			ErrAuthorizationQueryParametersError,
+			"X-Amz-Expires must be between 1 and %d seconds",
+			int64(MaxPresignExpiration/time.Second),
+		)
+	}
+
+	auth.presigned = true
+
+	return auth, nil
+}
+
+// newAuthorization parses the credential, of the form
+// <access key>/<date>/<region>/<service>/aws4_request.
+func newAuthorization(credential, signedHeaders, signature string) (*authorization, error) {
+	if credential == "" || signedHeaders == "" || signature == "" {
+		return nil, errors.New("missing credential, signed headers or signature")
+	}
+
+	parts := strings.Split(credential, "/")
+	if len(parts) < 5 || parts[len(parts)-1] != "aws4_request" {
+		return nil, fmt.Errorf("malformed credential %q", credential)
+	}
+
+	n := len(parts)
+
+	return &authorization{
+		accessKey:     strings.Join(parts[:n-4], "/"),
+		scope:         strings.Join(parts[n-4:], "/"),
+		region:        parts[n-3],
+		signedHeaders: strings.Split(signedHeaders, ";"),
+		signature:     signature,
+	}, nil
+}
+
+// checkDate returns the request date, once checked against the clock.
+func (v *Verifier) checkDate(r *http.Request, auth *authorization) (time.Time, error) {
+	var date time.Time
+
+	if auth.presigned {
+		var err error
+		date, err = time.Parse(dateFormatISO8601, r.URL.Query().Get("X-Amz-Date"))
+		if err != nil {
+			return time.Time{}, newVerificationError(ErrAuthorizationQueryParametersError, "X-Amz-Date must be in the ISO8601 format")
+		}
+	} else {
+		s := &signer{request: r, queryString: r.URL.Query()}
+		if err := s.extractDate(); err != nil {
+			return time.Time{}, newVerificationError(ErrAccessDenied, "%v", err)
+		}
+		date = s.date
+	}
+
+	maxSkew := v.MaxClockSkew
+	if maxSkew == 0 {
+		maxSkew = DefaultMaxClockSkew
+	}
+
+	now := v.now()
+
+	if auth.presigned {
+		if now.After(date.Add(auth.expires)) {
+			return time.Time{}, newVerificationError(ErrAccessDenied, "Request has expired")
+		}
+
+		if date.After(now.Add(maxSkew)) {
+			return time.Time{}, newVerificationError(ErrAccessDenied, "Request is not valid yet")
+		}
+
+		return date, nil
+	}
+
+	if skew := now.Sub(date); skew > maxSkew || skew < -maxSkew {
+		return time.Time{}, copyVerificationError(ErrRequestTimeTooSkewed)
+	}
+
+	return date, nil
+}
+
+// newSigner rebuilds the signer state of the client from the request, as the
+// server sees it.
+func (v *Verifier) newSigner(
+	r *http.Request,
+	auth *authorization,
+	credentials *config.Credentials,
+	date time.Time,
+) (*signer, error) {
+	signedHeaders := make(map[string]struct{}, len(auth.signedHeaders))
+	for _, name := range auth.signedHeaders {
+		signedHeaders[name] = struct{}{}
+	}
+
+	if _, ok := signedHeaders["host"]; !ok {
+		return nil, newVerificationError(ErrAuthorizationHeaderMalformed, "the host header must be signed")
+	}
+
+	// The server moves the Host header to the Host field.
+	request := r.WithContext(r.Context())
+	request.Header = r.Header.Clone()
+	if request.Header.Get("Host") == "" {
+		request.Header.Set("Host", r.Host)
+	}
+
+	queryString := r.URL.Query()
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+
+	if auth.presigned {
+		queryString.Del("X-Amz-Signature")
+		payloadHash = UnsignedPayload
+	} else if payloadHash == "" {
+		payload, err := v.readUnhashedPayload(r)
+		if err != nil {
+			return nil, err
+		}
+		payloadHash = fmt.Sprintf("%x", sha256.Sum256(payload))
+	}
+
+	s := &signer{
+		request:     request,
+		credentials: credentials,
+		region:      auth.region,
+		service:     v.service(),
+		includeHeader: func(name string) bool {
+			_, ok := signedHeaders[name]
+			return ok
+		},
+		doubleURIEncode: v.DoubleURIEncode,
+		queryString:     queryString,
+		payloadHash:     payloadHash,
+		date:            date,
+	}
+
+	s.computeScope()
+
+	return s, nil
+}
+
+// readUnhashedPayload buffers the body of a request which does not declare its
+// payload hash, which is only allowed outside of S3. The body is untrusted, so
+// at most maxUnhashedPayloadSize bytes are read.
+func (v *Verifier) readUnhashedPayload(r *http.Request) ([]byte, error) {
+	if v.service() == serviceS3 {
+		return nil, newVerificationError(ErrInvalidRequest, "missing required header for this request: x-amz-content-sha256")
+	}
+
+	if r.Body == nil {
+		return []byte{}, nil
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxUnhashedPayloadSize+1))
+	if err != nil {
+		return nil, newVerificationError(ErrIncompleteBody, "%v", err)
+	}
+
+	if len(payload) > maxUnhashedPayloadSize {
+		return nil, newVerificationError(ErrInvalidRequest, "payload without x-amz-content-sha256 larger than %d bytes", maxUnhashedPayloadSize)
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(payload))
+	r.ContentLength = int64(len(payload))
+
+	return payload, nil
+}
+
+// wrapBody replaces the body by one verifying the payload as it is read.
+func (v *Verifier) wrapBody(r *http.Request, s *signer, signingKey []byte, seedSignature string) error {
+	if r.Body == nil {
+		r.Body = http.NoBody
+	}
+
+	switch s.payloadHash {
+	case UnsignedPayload:
+		return nil
+	case StreamingPayload:
+		decodedLength, err := strconv.ParseInt(r.Header.Get("X-Amz-Decoded-Content-Length"), 10, 64)
+		if err != nil || decodedLength < 0 {
+			return newVerificationError(ErrInvalidRequest, "invalid X-Amz-Decoded-Content-Length")
+		}
+
+		r.Body = newChunkVerifier(r.Body, s, signingKey, seedSignature, decodedLength)
+		r.ContentLength = decodedLength
+	default:
+		r.Body = newPayloadVerifier(r.Body, s.payloadHash)
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package signing
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"strconv"
+)
+
+// maxChunkSize bounds the memory used to verify a streaming chunk, which must
+// be buffered until its signature is checked.
+const maxChunkSize = 16 * 1024 * 1024
+
+// maxChunkHeaderSize bounds the chunk header line: the hexadecimal size, the
+// signature and the separators.
+const maxChunkHeaderSize = 16 + len(chunkSignatureHeader) + chunkSignatureLength + len(chunkCRLF)
+
+// payloadVerifier hashes the body as it is read, and fails at its end when the
+// hash does not match the signed one.
+type payloadVerifier struct {
+	body     io.ReadCloser
+	hash     hash.Hash
+	expected string
+}
+
+func newPayloadVerifier(body io.ReadCloser, expected string) *payloadVerifier {
+	return &payloadVerifier{
+		body:     body,
+		hash:     sha256.New(),
+		expected: expected,
+	}
+}
+
+func (p *payloadVerifier) Read(b []byte) (int, error) {
+	n, err := p.body.Read(b)
+	p.hash.Write(b[:n])
+
+	if err == io.EOF && hex.EncodeToString(p.hash.Sum(nil)) != p.expected {
+		return n, copyVerificationError(ErrXAmzContentSHA256Mismatch)
+	}
+
+	return n, err
+}
+
+func (p *payloadVerifier) Close() error {
+	return p.body.Close()
+}
+
+// chunkVerifier decodes an aws-chunked body, only releasing the data of a
+// chunk once its signature is checked.
+type chunkVerifier struct {
+	body      *bufio.Reader
+	closer    io.Closer
+	remaining int64
+
+	signer            *signer
+	signingKey        []byte
+	previousSignature string
+
+	data   []byte
+	offset int
+	done   bool
+	err    error
+}
+
+func newChunkVerifier(body io.ReadCloser, s *signer, signingKey []byte, seedSignature string, decodedLength int64) *chunkVerifier {
+	return &chunkVerifier{
+		body:              bufio.NewReaderSize(body, maxChunkHeaderSize),
+		closer:            body,
+		remaining:         decodedLength,
+		signer:            s,
+		signingKey:        signingKey,
+		previousSignature: seedSignature,
+	}
+}
+
+func (c *chunkVerifier) Read(p []byte) (int, error) {
+	for c.offset == len(c.data) {
+		if c.err != nil {
+			return 0, c.err
+		}
+
+		if c.done {
+			return 0, io.EOF
+		}
+
+		c.err = c.nextChunk()
+	}
+
+	n := copy(p, c.data[c.offset:])
+	c.offset += n
+
+	return n, nil
+}
+
+func (c *chunkVerifier) Close() error {
+	return c.closer.Close()
+}
+
+func (c *chunkVerifier) nextChunk() error {
+	line, err := c.body.ReadSlice('\n')
+	if err != nil || !bytes.HasSuffix(line, []byte(chunkCRLF)) {
+		return newVerificationError(ErrIncompleteBody, "cannot read the chunk header")
+	}
+
+	rawSize, signature, found := bytes.Cut(bytes.TrimSuffix(line, []byte(chunkCRLF)), []byte(chunkSignatureHeader))
+	if !found {
+		return newVerificationError(ErrInvalidRequest, "malformed chunk header")
+	}
+
+	size, err := strconv.ParseInt(string(rawSize), 16, 64)
+	if err != nil || size < 0 || size > maxChunkSize {
+		return newVerificationError(ErrInvalidRequest, "invalid chunk size %q", rawSize)
+	}
+
+	if size > c.remaining {
+		return newVerificationError(ErrIncompleteBody, "the chunks exceed X-Amz-Decoded-Content-Length")
+	}
+
+	// Keep the signature, as the buffer of ReadSlice is reused by next reads.
+	expected := string(signature)
+
+	if int64(cap(c.data)) < size {
+		c.data = make([]byte, size)
+	}
+	c.data = c.data[:size]
+	c.offset = 0
+
+	if _, err := io.ReadFull(c.body, c.data); err != nil {
+		return newVerificationError(ErrIncompleteBody, "cannot read the chunk data")
+	}
+
+	crlf := make([]byte, len(chunkCRLF))
+	if _, err := io.ReadFull(c.body, crlf); err != nil || string(crlf) != chunkCRLF {
+		return newVerificationError(ErrIncompleteBody, "missing chunk trailer")
+	}
+
+	computed := c.signer.computeSignature(c.signingKey, computeChunkStringToSign(c.signer, c.previousSignature, c.data))
+	if !hmac.Equal([]byte(computed), []byte(expected)) {
+		// The data of a chunk whose signature does not match is never released.
+		c.data = c.data[:0]
+		return copyVerificationError(ErrSignatureDoesNotMatch)
+	}
+
+	c.previousSignature = computed
+	c.remaining -= size
+
+	if size == 0 {
+		c.done = true
+		if c.remaining != 0 {
+			return newVerificationError(ErrIncompleteBody, "the chunks are shorter than X-Amz-Decoded-Content-Length")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,398 @@
+package signing
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lvjp/raw-s3-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+var errUnknownKey = errors.New("unknown access key")
+
+func lookupCredentials(accessKey string) (config.Credentials, error) {
+	for _, c := range []config.Credentials{creds, sessionCreds} {
+		if c.AccessKey == accessKey {
+			return c, nil
+		}
+	}
+
+	return config.Credentials{}, errUnknownKey
+}
+
+// newVerifyServer verifies the requests it receives, reads their body and
+// answers with the error code, or OK.
+func newVerifyServer(t *testing.T, verifier *Verifier) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := verifier.Verify(r, lookupCredentials)
+		if err == nil {
+			var body []byte
+			body, err = io.ReadAll(r.Body)
+			if err == nil && r.Header.Get("X-Test-Expected-Body") != "" {
+				require.Equal(t, r.Header.Get("X-Test-Expected-Body"), string(body))
+			}
+		}
+
+		var verificationErr *VerificationError
+		switch {
+		case err == nil:
+			_, _ = w.Write([]byte("OK"))
+		case errors.As(err, &verificationErr):
+			w.WriteHeader(verificationErr.StatusCode)
+			_, _ = w.Write([]byte(verificationErr.Code))
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func send(t *testing.T, r *http.Request) string {
+	res, err := http.DefaultClient.Do(r)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	return string(body)
+}
+
+func TestVerify(t *testing.T) {
+	server := newVerifyServer(t, &Verifier{})
+
+	newSignedRequest := func(t *testing.T, method, path, body string, header map[string]string, credentials config.Credentials) *http.Request {
+		r, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		for name, value := range header {
+			r.Header.Set(name, value)
+		}
+		r.Header.Set("X-Test-Expected-Body", body)
+
+		require.NoError(t, Sign(r, credentials, "us-east-1"))
+
+		return r
+	}
+
+	largeBody := strings.Repeat("0123456789abcdef", ChunkSize/8)
+
+	testCases := map[string]struct {
+		request  func(t *testing.T) *http.Request
+		expected string
+	}{
+		"Get": {
+			request: func(t *testing.T) *http.Request {
+				return newSignedRequest(t, http.MethodGet, "/bucket/photos/my%20photo.jpg?versionId=1", "", nil, creds)
+			},
+			expected: "OK",
+		},
+		"PutHashed": {
+			request: func(t *testing.T) *http.Request {
+				return newSignedRequest(t, http.MethodPut, "/bucket/key", "hello", map[string]string{"Content-Type": "text/plain"}, creds)
+			},
+			expected: "OK",
+		},
+		"PutUnsigned": {
+			request: func(t *testing.T) *http.Request {
+				header := map[string]string{"X-Amz-Content-Sha256": UnsignedPayload}
+				return newSignedRequest(t, http.MethodPut, "/bucket/key", "hello", header, creds)
+			},
+			expected: "OK",
+		},
+		"PutStreaming": {
+			request: func(t *testing.T) *http.Request {
+				header := map[string]string{"X-Amz-Content-Sha256": StreamingPayload}
+				return newSignedRequest(t, http.MethodPut, "/bucket/key", largeBody, header, creds)
+			},
+			expected: "OK",
+		},
+		"SessionToken": {
+			request: func(t *testing.T) *http.Request {
+				return newSignedRequest(t, http.MethodGet, "/bucket/key", "", nil, sessionCreds)
+			},
+			expected: "OK",
+		},
+		"NotSigned": {
+			request: func(t *testing.T) *http.Request {
+				r, err := http.NewRequest(http.MethodGet, server.URL+"/bucket/key", http.NoBody)
+				require.NoError(t, err)
+				return r
+			},
+			expected: "AccessDenied",
+		},
+		"UnknownKey": {
+			request: func(t *testing.T) *http.Request {
+				return newSignedRequest(t, http.MethodGet, "/bucket/key", "", nil, config.Credentials{AccessKey: "AKIDUNKNOWN", SecretKey: "S"})
+			},
+			expected: "InvalidAccessKeyId",
+		},
+		"WrongSecret": {
+			request: func(t *testing.T) *http.Request {
+				return newSignedRequest(t, http.MethodGet, "/bucket/key", "", nil, config.Credentials{AccessKey: creds.AccessKey, SecretKey: "S"})
+			},
+			expected: "SignatureDoesNotMatch",
+		},
+		"TamperedHeader": {
+			request: func(t *testing.T) *http.Request {
+				r := newSignedRequest(t, http.MethodGet, "/bucket/key", "", map[string]string{"Range": "bytes=0-9"}, creds)
+				r.Header.Set("Range", "bytes=0-99")
+				return r
+			},
+			expected: "SignatureDoesNotMatch",
+		},
+		"TamperedPath": {
+			request: func(t *testing.T) *http.Request {
+				r := newSignedRequest(t, http.MethodGet, "/bucket/key", "", nil, creds)
+				r.URL.Path = "/bucket/other"
+				return r
+			},
+			expected: "SignatureDoesNotMatch",
+		},
+		"MissingToken": {
+			request: func(t *testing.T) *http.Request {
+				r := newSignedRequest(t, http.MethodGet, "/bucket/key", "", nil, sessionCreds)
+				r.Header.Del("X-Amz-Security-Token")
+				return r
+			},
+			expected: "SignatureDoesNotMatch",
+		},
+		"Skewed": {
+			request: func(t *testing.T) *http.Request {
+				date := time.Now().Add(-time.Hour).UTC().Format(dateFormatISO8601)
+				return newSignedRequest(t, http.MethodGet, "/bucket/key", "", map[string]string{"X-Amz-Date": date}, creds)
+			},
+			expected: "RequestTimeTooSkewed",
+		},
+		"PayloadMismatch": {
+			request: func(t *testing.T) *http.Request {
+				r := newSignedRequest(t, http.MethodPut, "/bucket/key", "hello", nil, creds)
+				r.Body = io.NopCloser(strings.NewReader("HELLO"))
+				return r
+			},
+			expected: "XAmzContentSHA256Mismatch",
+		},
+		"MissingPayloadHash": {
+			request: func(t *testing.T) *http.Request {
+				r := newSignedRequest(t, http.MethodPut, "/bucket/key", "hello", nil, creds)
+				r.Header.Del("X-Amz-Content-Sha256")
+				return r
+			},
+			expected: "InvalidRequest",
+		},
+		"MalformedHeader": {
+			request: func(t *testing.T) *http.Request {
+				r := newSignedRequest(t, http.MethodGet, "/bucket/key", "", nil, creds)
+				r.Header.Set("Authorization", algorithm+" Credential=AKID")
+				return r
+			},
+			expected: "AuthorizationHeaderMalformed",
+		},
+		"WrongService": {
+			request: func(t *testing.T) *http.Request {
+				r, err := http.NewRequest(http.MethodGet, server.URL+"/bucket/key", http.NoBody)
+				require.NoError(t, err)
+				require.NoError(t, (&Signer{Service: "sts"}).Sign(r, creds, "us-east-1"))
+				return r
+			},
+			expected: "AuthorizationHeaderMalformed",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, send(t, tc.request(t)))
+		})
+	}
+}
+
+// TestVerifyStreaming tampers with the encoded body, keeping the signed
+// headers, as a truncated or altered upload would be received.
+func TestVerifyStreaming(t *testing.T) {
+	body := strings.Repeat("x", ChunkSize+100)
+
+	testCases := map[string]struct {
+		tamper   func(encoded []byte) []byte
+		expected error
+	}{
+		"Valid": {
+			tamper: func(encoded []byte) []byte { return encoded },
+		},
+		"TamperedData": {
+			tamper: func(encoded []byte) []byte {
+				encoded[len(encoded)-200] = 'y'
+				return encoded
+			},
+			expected: ErrSignatureDoesNotMatch,
+		},
+		"Truncated": {
+			tamper: func(encoded []byte) []byte {
+				// Drop the last, empty, chunk.
+				return encoded[:len(encoded)-int(chunkLength(0))]
+			},
+			expected: ErrIncompleteBody,
+		},
+		"MissingChunk": {
+			tamper: func(encoded []byte) []byte {
+				// Drop the first chunk, so that the second does not chain anymore.
+				return encoded[chunkLength(ChunkSize):]
+			},
+			expected: ErrSignatureDoesNotMatch,
+		},
+		"TooLong": {
+			tamper: func(encoded []byte) []byte {
+				// Repeat the first chunk, which exceeds the decoded length.
+				first := encoded[:chunkLength(ChunkSize)]
+				return append(append([]byte{}, first...), encoded...)
+			},
+			expected: ErrIncompleteBody,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client, err := http.NewRequest(http.MethodPut, "http://example.com/bucket/key", strings.NewReader(body))
+			require.NoError(t, err)
+			client.Header.Set("X-Amz-Content-Sha256", StreamingPayload)
+			require.NoError(t, Sign(client, creds, "us-east-1"))
+
+			encoded, err := io.ReadAll(client.Body)
+			require.NoError(t, err)
+
+			r := httptest.NewRequest(http.MethodPut, "http://example.com/bucket/key", bytes.NewReader(tc.tamper(encoded)))
+			r.Header = client.Header
+
+			require.NoError(t, Verify(r, lookupCredentials))
+			require.Equal(t, int64(len(body)), r.ContentLength)
+
+			received, err := io.ReadAll(r.Body)
+			if tc.expected != nil {
+				require.ErrorIs(t, err, tc.expected)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, body, string(received))
+		})
+	}
+}
+
+func TestVerifyPresigned(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	server := newVerifyServer(t, &Verifier{Now: func() time.Time { return now }})
+
+	testCases := map[string]struct {
+		signedAt    time.Time
+		credentials config.Credentials
+		tamper      func(r *http.Request)
+		expected    string
+	}{
+		"Valid":        {signedAt: now, credentials: creds, expected: "OK"},
+		"SessionToken": {signedAt: now, credentials: sessionCreds, expected: "OK"},
+		"Expired":      {signedAt: now.Add(-time.Hour), credentials: creds, expected: "AccessDenied"},
+		"NotYetValid":  {signedAt: now.Add(time.Hour), credentials: creds, expected: "AccessDenied"},
+		"TamperedQuery": {
+			signedAt:    now,
+			credentials: creds,
+			tamper: func(r *http.Request) {
+				query := r.URL.Query()
+				query.Set("X-Amz-Expires", "1800")
+				r.URL.RawQuery = query.Encode()
+			},
+			expected: "SignatureDoesNotMatch",
+		},
+		"BadExpires": {
+			signedAt:    now,
+			credentials: creds,
+			tamper: func(r *http.Request) {
+				query := r.URL.Query()
+				query.Set("X-Amz-Expires", "0")
+				r.URL.RawQuery = query.Encode()
+			},
+			expected: "AuthorizationQueryParametersError",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodGet, server.URL+"/bucket/key", http.NoBody)
+			require.NoError(t, err)
+			require.NoError(t, presign(r, tc.credentials, "us-east-1", 15*time.Minute, tc.signedAt))
+
+			if tc.tamper != nil {
+				tc.tamper(r)
+			}
+
+			require.Equal(t, tc.expected, send(t, r))
+		})
+	}
+}
+
+func TestVerifySignerTestSuite(t *testing.T) {
+	verifier := &Verifier{Service: "service", DoubleURIEncode: true, Now: func() time.Time { return suiteDate }}
+
+	r := httptest.NewRequest(http.MethodPost, "https://example.amazonaws.com/", strings.NewReader("Param1=value1"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Amz-Date", "20150830T123600Z")
+	r.Header.Set(
+		"Authorization",
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+			"SignedHeaders=content-type;host;x-amz-date, "+
+			"Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+	)
+
+	lookup := func(string) (config.Credentials, error) { return suiteCreds, nil }
+	require.NoError(t, verifier.Verify(r, lookup))
+
+	body, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	require.Equal(t, "Param1=value1", string(body))
+}
+
+func TestVerifyUnhashedPayloadTooLarge(t *testing.T) {
+	verifier := &Verifier{Service: "service", DoubleURIEncode: true}
+	body := strings.Repeat("a", maxUnhashedPayloadSize+1)
+
+	r := httptest.NewRequest(http.MethodPost, "https://example.amazonaws.com/", strings.NewReader(body))
+	require.NoError(t, NewSigner("service").Sign(r, creds, "us-east-1"))
+	require.Empty(t, r.Header.Get("X-Amz-Content-Sha256"))
+
+	lookup := func(string) (config.Credentials, error) { return creds, nil }
+	require.ErrorIs(t, verifier.Verify(r, lookup), ErrInvalidRequest)
+}
+
+func TestVerificationErrorIs(t *testing.T) {
+	err := newVerificationError(ErrAccessDenied, "Request has expired")
+
+	require.ErrorIs(t, err, ErrAccessDenied)
+	require.NotErrorIs(t, err, ErrSignatureDoesNotMatch)
+	require.Equal(t, "AccessDenied: Request has expired", err.Error())
+
+	wrapped := &VerificationError{Code: ErrInvalidAccessKeyID.Code, Err: errUnknownKey}
+	require.ErrorIs(t, wrapped, ErrInvalidAccessKeyID)
+	require.ErrorIs(t, wrapped, errUnknownKey)
+}
+
+func TestVerifyReturnsCopies(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "https://bucket.s3.amazonaws.com/key", nil)
+	require.NoError(t, Sign(r, creds, "us-east-1"))
+	r.URL.Path = "/other"
+
+	lookup := func(string) (config.Credentials, error) { return creds, nil }
+	err := (&Verifier{}).Verify(r, lookup)
+	require.ErrorIs(t, err, ErrSignatureDoesNotMatch)
+
+	var verr *VerificationError
+	require.ErrorAs(t, err, &verr)
+	require.NotSame(t, ErrSignatureDoesNotMatch, verr)
+
+	verr.Message = "modified"
+	require.NotEqual(t, "modified", ErrSignatureDoesNotMatch.Message)
+}
@@ -0,0 +1,24 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var _ AWSConvertible[s3.CopyObjectOutput] = (*CopyObjectResult)(nil)
+
+type CopyObjectResult struct {
+	ETag         *string
+	LastModified *string
+}
+
+func (cor *CopyObjectResult) ToAWS(t *testing.T) *s3.CopyObjectOutput {
+	return &s3.CopyObjectOutput{
+		CopyObjectResult: &types.CopyObjectResult{
+			ETag:         cor.ETag,
+			LastModified: parseTime(t, cor.LastModified),
+		},
+	}
+}
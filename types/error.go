@@ -0,0 +1,24 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+var _ AWSConvertible[smithy.GenericAPIError] = (*Error)(nil)
+
+type Error struct {
+	Code      string
+	Message   string
+	Resource  string
+	RequestID string `xml:"RequestId"`
+	HostID    string `xml:"HostId"`
+}
+
+func (e *Error) ToAWS(t *testing.T) *smithy.GenericAPIError {
+	return &smithy.GenericAPIError{
+		Code:    e.Code,
+		Message: e.Message,
+	}
+}
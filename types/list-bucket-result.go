@@ -0,0 +1,142 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var (
+	_ AWSConvertible[s3.ListObjectsOutput]   = (*ListBucketResult)(nil)
+	_ AWSConvertible[s3.ListObjectsV2Output] = (*ListBucketResultV2)(nil)
+)
+
+// ListBucketResult is the ListObjects (version 1) response.
+type ListBucketResult struct {
+	Name           *string
+	Prefix         *string
+	Marker         *string
+	NextMarker     *string
+	MaxKeys        int32
+	Delimiter      *string
+	EncodingType   *string
+	IsTruncated    bool
+	Contents       []Object       `xml:"Contents"`
+	CommonPrefixes []CommonPrefix `xml:"CommonPrefixes"`
+}
+
+// ListBucketResultV2 is the ListObjectsV2 response, whose root element is
+// also ListBucketResult.
+type ListBucketResultV2 struct {
+	XMLName struct{} `xml:"ListBucketResult"`
+
+	Name                  *string
+	Prefix                *string
+	StartAfter            *string
+	ContinuationToken     *string
+	NextContinuationToken *string
+	KeyCount              int32
+	MaxKeys               int32
+	Delimiter             *string
+	EncodingType          *string
+	IsTruncated           bool
+	Contents              []Object       `xml:"Contents"`
+	CommonPrefixes        []CommonPrefix `xml:"CommonPrefixes"`
+}
+
+type Object struct {
+	Key          *string
+	LastModified *string
+	ETag         *string
+	Size         int64
+	StorageClass *string
+	Owner        *Owner
+}
+
+func (lbr *ListBucketResult) ToAWS(t *testing.T) *s3.ListObjectsOutput {
+	result := &s3.ListObjectsOutput{
+		Name:           lbr.Name,
+		Prefix:         lbr.Prefix,
+		Marker:         lbr.Marker,
+		NextMarker:     lbr.NextMarker,
+		MaxKeys:        lbr.MaxKeys,
+		Delimiter:      lbr.Delimiter,
+		IsTruncated:    lbr.IsTruncated,
+		Contents:       objectsToAWS(t, lbr.Contents),
+		CommonPrefixes: commonPrefixesToAWS(lbr.CommonPrefixes),
+	}
+
+	if lbr.EncodingType != nil {
+		result.EncodingType = types.EncodingType(*lbr.EncodingType)
+	}
+
+	return result
+}
+
+func (lbr *ListBucketResultV2) ToAWS(t *testing.T) *s3.ListObjectsV2Output {
+	result := &s3.ListObjectsV2Output{
+		Name:                  lbr.Name,
+		Prefix:                lbr.Prefix,
+		StartAfter:            lbr.StartAfter,
+		ContinuationToken:     lbr.ContinuationToken,
+		NextContinuationToken: lbr.NextContinuationToken,
+		KeyCount:              lbr.KeyCount,
+		MaxKeys:               lbr.MaxKeys,
+		Delimiter:             lbr.Delimiter,
+		IsTruncated:           lbr.IsTruncated,
+		Contents:              objectsToAWS(t, lbr.Contents),
+		CommonPrefixes:        commonPrefixesToAWS(lbr.CommonPrefixes),
+	}
+
+	if lbr.EncodingType != nil {
+		result.EncodingType = types.EncodingType(*lbr.EncodingType)
+	}
+
+	return result
+}
+
+func (o *Object) ToAWS(t *testing.T) *types.Object {
+	result := &types.Object{
+		Key:          o.Key,
+		LastModified: parseTime(t, o.LastModified),
+		ETag:         o.ETag,
+		Size:         o.Size,
+	}
+
+	if o.StorageClass != nil {
+		result.StorageClass = types.ObjectStorageClass(*o.StorageClass)
+	}
+
+	if o.Owner != nil {
+		result.Owner = o.Owner.ToAWS()
+	}
+
+	return result
+}
+
+func objectsToAWS(t *testing.T, objects []Object) []types.Object {
+	if objects == nil {
+		return nil
+	}
+
+	result := make([]types.Object, 0, len(objects))
+	for _, object := range objects {
+		result = append(result, *object.ToAWS(t))
+	}
+
+	return result
+}
+
+func commonPrefixesToAWS(prefixes []CommonPrefix) []types.CommonPrefix {
+	if prefixes == nil {
+		return nil
+	}
+
+	result := make([]types.CommonPrefix, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		result = append(result, types.CommonPrefix{Prefix: prefix.Prefix})
+	}
+
+	return result
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var _ AWSConvertible[s3.ListMultipartUploadsOutput] = (*ListMultipartUploadsResult)(nil)
+
+type ListMultipartUploadsResult struct {
+	Bucket             *string
+	KeyMarker          *string
+	UploadIDMarker     *string `xml:"UploadIdMarker"`
+	NextKeyMarker      *string
+	NextUploadIDMarker *string `xml:"NextUploadIdMarker"`
+	Prefix             *string
+	Delimiter          *string
+	EncodingType       *string
+	MaxUploads         int32
+	IsTruncated        bool
+	Uploads            []Upload       `xml:"Upload"`
+	CommonPrefixes     []CommonPrefix `xml:"CommonPrefixes"`
+}
+
+type Upload struct {
+	Key          *string
+	UploadID     *string `xml:"UploadId"`
+	Initiator    *Initiator
+	Owner        *Owner
+	StorageClass *string
+	Initiated    *string
+}
+
+type CommonPrefix struct {
+	Prefix *string
+}
+
+func (lmur *ListMultipartUploadsResult) ToAWS(t *testing.T) *s3.ListMultipartUploadsOutput {
+	result := &s3.ListMultipartUploadsOutput{
+		Bucket:             lmur.Bucket,
+		KeyMarker:          lmur.KeyMarker,
+		UploadIdMarker:     lmur.UploadIDMarker,
+		NextKeyMarker:      lmur.NextKeyMarker,
+		NextUploadIdMarker: lmur.NextUploadIDMarker,
+		Prefix:             lmur.Prefix,
+		Delimiter:          lmur.Delimiter,
+		MaxUploads:         lmur.MaxUploads,
+		IsTruncated:        lmur.IsTruncated,
+	}
+
+	if lmur.EncodingType != nil {
+		result.EncodingType = types.EncodingType(*lmur.EncodingType)
+	}
+
+	if lmur.Uploads != nil {
+		result.Uploads = make([]types.MultipartUpload, 0, len(lmur.Uploads))
+		for _, upload := range lmur.Uploads {
+			result.Uploads = append(result.Uploads, *upload.ToAWS(t))
+		}
+	}
+
+	result.CommonPrefixes = commonPrefixesToAWS(lmur.CommonPrefixes)
+
+	return result
+}
+
+func (u *Upload) ToAWS(t *testing.T) *types.MultipartUpload {
+	result := &types.MultipartUpload{
+		Key:       u.Key,
+		UploadId:  u.UploadID,
+		Initiated: parseTime(t, u.Initiated),
+	}
+
+	if u.Initiator != nil {
+		result.Initiator = u.Initiator.ToAWS()
+	}
+
+	if u.Owner != nil {
+		result.Owner = u.Owner.ToAWS()
+	}
+
+	if u.StorageClass != nil {
+		result.StorageClass = types.StorageClass(*u.StorageClass)
+	}
+
+	return result
+}
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var _ AWSConvertible[s3.ListPartsOutput] = (*ListPartsResult)(nil)
+
+type ListPartsResult struct {
+	Bucket               *string
+	Key                  *string
+	UploadID             *string `xml:"UploadId"`
+	Initiator            *Initiator
+	Owner                *Owner
+	StorageClass         *string
+	PartNumberMarker     *string
+	NextPartNumberMarker *string
+	MaxParts             int32
+	IsTruncated          bool
+	Parts                []Part `xml:"Part"`
+}
+
+type Part struct {
+	PartNumber   int32
+	LastModified *string
+	ETag         *string
+	Size         int64
+}
+
+func (lpr *ListPartsResult) ToAWS(t *testing.T) *s3.ListPartsOutput {
+	result := &s3.ListPartsOutput{
+		Bucket:               lpr.Bucket,
+		Key:                  lpr.Key,
+		UploadId:             lpr.UploadID,
+		PartNumberMarker:     lpr.PartNumberMarker,
+		NextPartNumberMarker: lpr.NextPartNumberMarker,
+		MaxParts:             lpr.MaxParts,
+		IsTruncated:          lpr.IsTruncated,
+	}
+
+	if lpr.Initiator != nil {
+		result.Initiator = lpr.Initiator.ToAWS()
+	}
+
+	if lpr.Owner != nil {
+		result.Owner = lpr.Owner.ToAWS()
+	}
+
+	if lpr.StorageClass != nil {
+		result.StorageClass = types.StorageClass(*lpr.StorageClass)
+	}
+
+	if lpr.Parts != nil {
+		result.Parts = make([]types.Part, 0, len(lpr.Parts))
+		for _, part := range lpr.Parts {
+			result.Parts = append(result.Parts, *part.ToAWS(t))
+		}
+	}
+
+	return result
+}
+
+func (p *Part) ToAWS(t *testing.T) *types.Part {
+	return &types.Part{
+		PartNumber:   p.PartNumber,
+		LastModified: parseTime(t, p.LastModified),
+		ETag:         p.ETag,
+		Size:         p.Size,
+	}
+}
@@ -0,0 +1,116 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var _ AWSConvertible[s3.ListObjectVersionsOutput] = (*ListVersionsResult)(nil)
+
+type ListVersionsResult struct {
+	Name                *string
+	Prefix              *string
+	KeyMarker           *string
+	VersionIDMarker     *string `xml:"VersionIdMarker"`
+	NextKeyMarker       *string
+	NextVersionIDMarker *string `xml:"NextVersionIdMarker"`
+	MaxKeys             int32
+	Delimiter           *string
+	EncodingType        *string
+	IsTruncated         bool
+	Versions            []ObjectVersion     `xml:"Version"`
+	DeleteMarkers       []DeleteMarkerEntry `xml:"DeleteMarker"`
+	CommonPrefixes      []CommonPrefix      `xml:"CommonPrefixes"`
+}
+
+type ObjectVersion struct {
+	Key          *string
+	VersionID    *string `xml:"VersionId"`
+	IsLatest     bool
+	LastModified *string
+	ETag         *string
+	Size         int64
+	StorageClass *string
+	Owner        *Owner
+}
+
+type DeleteMarkerEntry struct {
+	Key          *string
+	VersionID    *string `xml:"VersionId"`
+	IsLatest     bool
+	LastModified *string
+	Owner        *Owner
+}
+
+func (lvr *ListVersionsResult) ToAWS(t *testing.T) *s3.ListObjectVersionsOutput {
+	result := &s3.ListObjectVersionsOutput{
+		Name:                lvr.Name,
+		Prefix:              lvr.Prefix,
+		KeyMarker:           lvr.KeyMarker,
+		VersionIdMarker:     lvr.VersionIDMarker,
+		NextKeyMarker:       lvr.NextKeyMarker,
+		NextVersionIdMarker: lvr.NextVersionIDMarker,
+		MaxKeys:             lvr.MaxKeys,
+		Delimiter:           lvr.Delimiter,
+		IsTruncated:         lvr.IsTruncated,
+		CommonPrefixes:      commonPrefixesToAWS(lvr.CommonPrefixes),
+	}
+
+	if lvr.EncodingType != nil {
+		result.EncodingType = types.EncodingType(*lvr.EncodingType)
+	}
+
+	if lvr.Versions != nil {
+		result.Versions = make([]types.ObjectVersion, 0, len(lvr.Versions))
+		for _, version := range lvr.Versions {
+			result.Versions = append(result.Versions, *version.ToAWS(t))
+		}
+	}
+
+	if lvr.DeleteMarkers != nil {
+		result.DeleteMarkers = make([]types.DeleteMarkerEntry, 0, len(lvr.DeleteMarkers))
+		for _, marker := range lvr.DeleteMarkers {
+			result.DeleteMarkers = append(result.DeleteMarkers, *marker.ToAWS(t))
+		}
+	}
+
+	return result
+}
+
+func (ov *ObjectVersion) ToAWS(t *testing.T) *types.ObjectVersion {
+	result := &types.ObjectVersion{
+		Key:          ov.Key,
+		VersionId:    ov.VersionID,
+		IsLatest:     ov.IsLatest,
+		LastModified: parseTime(t, ov.LastModified),
+		ETag:         ov.ETag,
+		Size:         ov.Size,
+	}
+
+	if ov.StorageClass != nil {
+		result.StorageClass = types.ObjectVersionStorageClass(*ov.StorageClass)
+	}
+
+	if ov.Owner != nil {
+		result.Owner = ov.Owner.ToAWS()
+	}
+
+	return result
+}
+
+func (dme *DeleteMarkerEntry) ToAWS(t *testing.T) *types.DeleteMarkerEntry {
+	result := &types.DeleteMarkerEntry{
+		Key:          dme.Key,
+		VersionId:    dme.VersionID,
+		IsLatest:     dme.IsLatest,
+		LastModified: parseTime(t, dme.LastModified),
+	}
+
+	if dme.Owner != nil {
+		result.Owner = dme.Owner.ToAWS()
+	}
+
+	return result
+}
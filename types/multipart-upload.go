@@ -0,0 +1,95 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ AWSConvertible[s3.CreateMultipartUploadOutput]   = (*InitiateMultipartUploadResult)(nil)
+	_ AWSConvertible[s3.CompleteMultipartUploadOutput] = (*CompleteMultipartUploadResult)(nil)
+	_ AWSConvertible[s3.UploadPartCopyOutput]          = (*CopyPartResult)(nil)
+)
+
+type InitiateMultipartUploadResult struct {
+	Bucket   *string
+	Key      *string
+	UploadID *string `xml:"UploadId"`
+}
+
+// CompleteMultipartUpload is the request body of CompleteMultipartUpload.
+type CompleteMultipartUpload struct {
+	XMLName struct{}        `xml:"CompleteMultipartUpload"`
+	Parts   []CompletedPart `xml:"Part"`
+}
+
+type CompletedPart struct {
+	ETag       *string
+	PartNumber int32
+}
+
+type CompleteMultipartUploadResult struct {
+	Location *string
+	Bucket   *string
+	Key      *string
+	ETag     *string
+}
+
+type CopyPartResult struct {
+	ETag         *string
+	LastModified *string
+}
+
+type Initiator struct {
+	DisplayName *string
+	ID          *string
+}
+
+func (imur *InitiateMultipartUploadResult) ToAWS(t *testing.T) *s3.CreateMultipartUploadOutput {
+	return &s3.CreateMultipartUploadOutput{
+		Bucket:   imur.Bucket,
+		Key:      imur.Key,
+		UploadId: imur.UploadID,
+	}
+}
+
+func (cmur *CompleteMultipartUploadResult) ToAWS(t *testing.T) *s3.CompleteMultipartUploadOutput {
+	return &s3.CompleteMultipartUploadOutput{
+		Location: cmur.Location,
+		Bucket:   cmur.Bucket,
+		Key:      cmur.Key,
+		ETag:     cmur.ETag,
+	}
+}
+
+func (cpr *CopyPartResult) ToAWS(t *testing.T) *s3.UploadPartCopyOutput {
+	return &s3.UploadPartCopyOutput{
+		CopyPartResult: &types.CopyPartResult{
+			ETag:         cpr.ETag,
+			LastModified: parseTime(t, cpr.LastModified),
+		},
+	}
+}
+
+func (i *Initiator) ToAWS() *types.Initiator {
+	return &types.Initiator{
+		DisplayName: i.DisplayName,
+		ID:          i.ID,
+	}
+}
+
+// parseTime converts the RFC 3339 timestamps of the XML documents.
+func parseTime(t *testing.T, value *string) *time.Time {
+	if value == nil {
+		return nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, *value)
+	require.NoError(t, err, "Cannot parse time '%s'", *value)
+
+	return &parsed
+}
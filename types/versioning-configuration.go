@@ -0,0 +1,34 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var _ AWSConvertible[s3.GetBucketVersioningOutput] = (*VersioningConfiguration)(nil)
+
+// VersioningConfiguration is both the GetBucketVersioning response and the
+// PutBucketVersioning request body. Status is Enabled or Suspended, and is
+// missing from buckets never versioned.
+type VersioningConfiguration struct {
+	XMLName struct{} `xml:"VersioningConfiguration"`
+
+	Status    *string `xml:",omitempty"`
+	MFADelete *string `xml:"MfaDelete,omitempty"`
+}
+
+func (vc *VersioningConfiguration) ToAWS(t *testing.T) *s3.GetBucketVersioningOutput {
+	result := &s3.GetBucketVersioningOutput{}
+
+	if vc.Status != nil {
+		result.Status = types.BucketVersioningStatus(*vc.Status)
+	}
+
+	if vc.MFADelete != nil {
+		result.MFADelete = types.MFADeleteStatus(*vc.MFADelete)
+	}
+
+	return result
+}